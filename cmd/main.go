@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/audit"
 	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/events"
+	"github.com/ruziba3vich/boock/internal/items/gql"
+	"github.com/ruziba3vich/boock/internal/items/grpcserver"
 	"github.com/ruziba3vich/boock/internal/items/http/app"
 	"github.com/ruziba3vich/boock/internal/items/http/handler"
+	"github.com/ruziba3vich/boock/internal/items/outbox"
+	"github.com/ruziba3vich/boock/internal/items/purger"
 	"github.com/ruziba3vich/boock/internal/items/redisservice"
 	"github.com/ruziba3vich/boock/internal/items/service"
 	"github.com/ruziba3vich/boock/internal/items/storage"
+	"github.com/ruziba3vich/boock/internal/items/webhook"
 	redisCl "github.com/ruziba3vich/boock/internal/pkg/redis"
 )
 
@@ -27,27 +36,60 @@ func main() {
 		logger.Fatalln(err)
 	}
 
-	redis, err := redisCl.NewRedisDB(config)
+	redis, err := redisCl.NewRedisDB(config, logger)
 	if err != nil {
 		logger.Fatalln(err)
 	}
 
 	sqrl := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	handler := handler.New(
-		service.New(
+	redisService := redisservice.New(redis, config.Redis, logger)
+	auditLog := storage.NewAuditStorage(db, sqrl, config, logger)
+	validator := service.NewValidator(config.Validation)
+	bookService := service.New(
+		audit.New(
 			storage.New(
-				redisservice.New(
-					redis,
-					logger,
-				),
+				redisService,
 				db,
 				sqrl,
 				config,
+				validator,
+				audit.NewObserver(),
 				logger,
 			),
-		), logger,
+			auditLog,
+			logger,
+		),
+		validator,
 	)
+	webhooks := service.NewWebhookService(storage.NewWebhookStorage(db, sqrl, config, logger))
+	hub := events.NewHub(redisService, config.Outbox.Stream, config.Events, logger)
+	graphql, err := gql.New(bookService, redisService, config.GraphQL, config.Server.MaxPageSize, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	handler := handler.New(bookService, auditLog, webhooks, hub, graphql, logger)
+
+	go hub.Run(context.Background())
+	go redisService.ListenInvalidations(context.Background())
+	go purger.New(bookService, config.Trash, logger).Run(context.Background())
+	go outbox.New(
+		storage.NewOutboxStorage(db, sqrl, config, logger),
+		redisService,
+		config.Outbox,
+		config.Events.Channel,
+		logger,
+	).Run(context.Background())
+	go webhook.New(
+		webhooks,
+		&http.Client{Timeout: config.Webhooks.Timeout},
+		config.Webhooks,
+		logger,
+	).Run(context.Background())
+
+	go func() {
+		logger.Fatalln(grpcserver.Run(grpcserver.New(bookService, config.Trash.Retention, logger), logger, config.Server.GRPCPort))
+	}()
 
-	logger.Fatalln(app.Run(gin.Default(), handler, logger, config.Server.Port))
+	logger.Fatalln(app.Run(gin.Default(), handler, config.API, config.Server.Port))
 }
@@ -0,0 +1,72 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies a failure independently of the transport that reports it.
+type Kind int
+
+const (
+	Internal Kind = iota
+	Invalid
+	NotFound
+	Conflict
+	Validation
+	Unavailable
+	PreconditionFailed
+)
+
+type (
+	Error struct {
+		Kind    Kind
+		Message string
+		Details []Detail
+		Err     error
+	}
+	// Detail points at the single field or query term that caused the error.
+	Detail struct {
+		Field    string `json:"field,omitempty"`
+		Param    string `json:"param,omitempty"`
+		Term     string `json:"term,omitempty"`
+		Operator string `json:"operator,omitempty"`
+		Reason   string `json:"reason"`
+	}
+)
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(kind Kind, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func Wrap(kind Kind, err error, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+func WithDetails(kind Kind, details []Detail, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Details: details}
+}
+
+// KindOf reports the kind of the first *Error in err's chain, or Internal.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return Internal
+}
+
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
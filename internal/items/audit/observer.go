@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// Observer carries the states the storage read inside a mutation's
+	// transaction back up to the Repo that asked for the mutation, so the
+	// diff cannot pick up a concurrent change. The storage under a Repo must
+	// report to one.
+	Observer struct{}
+
+	// observed is where Observer leaves the states for the Repo.
+	observed struct {
+		before, after *models.Book
+	}
+
+	observedKey struct{}
+)
+
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+func (*Observer) BookChanged(ctx context.Context, before, after *models.Book) {
+	if o, ok := ctx.Value(observedKey{}).(*observed); ok {
+		o.before, o.after = before, after
+	}
+}
+
+// observe returns a context whose mutation is reported to the returned
+// observed.
+func observe(ctx context.Context) (context.Context, *observed) {
+	o := &observed{}
+	return context.WithValue(ctx, observedKey{}, o), o
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"log"
+
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+	"github.com/ruziba3vich/boock/internal/pkg/reqmeta"
+)
+
+const (
+	OpCreate  = "create"
+	OpUpdate  = "update"
+	OpPatch   = "patch"
+	OpDelete  = "delete"
+	OpRestore = "restore"
+	OpRevert  = "revert"
+	OpPurge   = "purge"
+)
+
+type (
+	// Repo wraps the book storage and reports every mutation, with the state
+	// of the book before and after it, to the audit log.
+	Repo struct {
+		storage  repository.IBookRepo
+		auditLog repository.IAuditRepo
+		logger   *log.Logger
+	}
+)
+
+func New(storage repository.IBookRepo, auditLog repository.IAuditRepo, logger *log.Logger) repository.IBookRepo {
+	return &Repo{
+		storage:  storage,
+		auditLog: auditLog,
+		logger:   logger,
+	}
+}
+
+func (r *Repo) CreateBook(ctx context.Context, req *models.CreateBookRequest) (*models.Book, error) {
+	book, err := r.storage.CreateBook(ctx, req)
+	bookId := ""
+	if book != nil {
+		bookId = book.BookId
+	}
+	r.record(ctx, OpCreate, bookId, nil, book, err)
+	return book, err
+}
+func (r *Repo) UpdateBook(ctx context.Context, req *models.UpdateBookRequest) (*models.Book, error) {
+	ctx, states := observe(ctx)
+	book, err := r.storage.UpdateBook(ctx, req)
+	r.record(ctx, OpUpdate, req.BookId, states.before, book, err)
+	return book, err
+}
+func (r *Repo) PatchBook(ctx context.Context, req *models.PatchBookRequest) (*models.Book, error) {
+	ctx, states := observe(ctx)
+	book, err := r.storage.PatchBook(ctx, req)
+	r.record(ctx, OpPatch, req.BookId, states.before, book, err)
+	return book, err
+}
+func (r *Repo) GetBookById(ctx context.Context, req *models.GetBookByIdRequest) (*models.Book, error) {
+	return r.storage.GetBookById(ctx, req)
+}
+func (r *Repo) GetAllBooks(ctx context.Context, req *models.GetAllBooksRequest) (*models.GetSeveralResponse, error) {
+	return r.storage.GetAllBooks(ctx, req)
+}
+func (r *Repo) GetBooksByAuthor(ctx context.Context, req *models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error) {
+	return r.storage.GetBooksByAuthor(ctx, req)
+}
+func (r *Repo) GetBooksByName(ctx context.Context, req *models.GetBooksByNameRequest) (*models.GetSeveralResponse, error) {
+	return r.storage.GetBooksByName(ctx, req)
+}
+func (r *Repo) SearchBooks(ctx context.Context, req *models.SearchBooksRequest) (*models.SearchBooksResponse, error) {
+	return r.storage.SearchBooks(ctx, req)
+}
+func (r *Repo) DeleteBookById(ctx context.Context, req *models.DeleteBookByIdRequest) error {
+	ctx, states := observe(ctx)
+	err := r.storage.DeleteBookById(ctx, req)
+	r.record(ctx, OpDelete, req.BookId, states.before, states.after, err)
+	return err
+}
+func (r *Repo) GetCatalogVersion(ctx context.Context) (*models.CatalogVersion, error) {
+	return r.storage.GetCatalogVersion(ctx)
+}
+func (r *Repo) ListTrash(ctx context.Context, req *models.ListTrashRequest) (*models.GetSeveralResponse, error) {
+	return r.storage.ListTrash(ctx, req)
+}
+func (r *Repo) RestoreBook(ctx context.Context, req *models.RestoreBookRequest) (*models.Book, error) {
+	ctx, states := observe(ctx)
+	book, err := r.storage.RestoreBook(ctx, req)
+	r.record(ctx, OpRestore, req.BookId, states.before, book, err)
+	return book, err
+}
+func (r *Repo) PurgeDeletedBooks(ctx context.Context, req *models.PurgeDeletedBooksRequest) (*models.PurgeDeletedBooksResponse, error) {
+	response, err := r.storage.PurgeDeletedBooks(ctx, req)
+	change := Change{Operation: OpPurge, Err: err}
+	if response != nil {
+		change.Diff = map[string]models.AuditChange{"purged": {After: response.Purged}}
+	}
+	r.note(ctx, change)
+	return response, err
+}
+func (r *Repo) GetBookHistory(ctx context.Context, req *models.GetBookHistoryRequest) (*models.GetBookHistoryResponse, error) {
+	return r.storage.GetBookHistory(ctx, req)
+}
+func (r *Repo) RevertBook(ctx context.Context, req *models.RevertBookRequest) (*models.Book, error) {
+	ctx, states := observe(ctx)
+	book, err := r.storage.RevertBook(ctx, req)
+	r.record(ctx, OpRevert, req.BookId, states.before, book, err)
+	return book, err
+}
+
+func (r *Repo) record(ctx context.Context, operation, bookId string, before, after *models.Book, err error) {
+	change := Change{Operation: operation, BookId: bookId, Err: err}
+	if err == nil {
+		change.Diff = Diff(before, after)
+	}
+	r.note(ctx, change)
+}
+
+// note hands change to the request's trail, or writes it on its own when the
+// mutation did not come through the HTTP API.
+func (r *Repo) note(ctx context.Context, change Change) {
+	if trail := trailFrom(ctx); trail != nil {
+		trail.add(change)
+		return
+	}
+
+	meta := reqmeta.From(ctx)
+	entry := &models.AuditEntry{
+		Actor:     meta.Actor,
+		IP:        meta.IP,
+		Method:    meta.Method,
+		Route:     meta.Route,
+		RequestId: meta.RequestId,
+		Operation: change.Operation,
+		BookId:    change.BookId,
+		Diff:      change.Diff,
+		Outcome:   models.AuditSuccess,
+	}
+	if change.Err != nil {
+		entry.Outcome = models.AuditFailure
+		entry.Error = change.Err.Error()
+	}
+	if err := r.auditLog.AppendAuditEntry(ctx, entry); err != nil {
+		r.logger.Println("Error writing audit entry:", err)
+	}
+}
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// Change is what the storage hook learned about one mutation.
+	Change struct {
+		Operation string
+		BookId    string
+		Diff      map[string]models.AuditChange
+		Err       error
+	}
+
+	// Trail collects the changes made while serving one request, so the HTTP
+	// middleware can write them in a single entry together with the outcome.
+	Trail struct {
+		mu      sync.Mutex
+		changes []Change
+	}
+
+	trailKey struct{}
+)
+
+// Track returns a context whose mutations are collected in the returned trail
+// instead of being written to the audit log straight away.
+func Track(ctx context.Context) (context.Context, *Trail) {
+	trail := &Trail{}
+	return context.WithValue(ctx, trailKey{}, trail), trail
+}
+
+func trailFrom(ctx context.Context) *Trail {
+	trail, _ := ctx.Value(trailKey{}).(*Trail)
+	return trail
+}
+
+func (t *Trail) add(change Change) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.changes = append(t.changes, change)
+}
+
+func (t *Trail) Changes() []Change {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Change(nil), t.changes...)
+}
+
+// Diff compares the JSON form of two versions of a book field by field. A nil
+// book stands for "did not exist", so every field shows up as changed.
+func Diff(before, after *models.Book) map[string]models.AuditChange {
+	beforeFields, afterFields := bookFields(before), bookFields(after)
+
+	diff := map[string]models.AuditChange{}
+	for field, value := range afterFields {
+		if previous, ok := beforeFields[field]; !ok || !jsonEqual(previous, value) {
+			diff[field] = models.AuditChange{Before: beforeFields[field], After: value}
+		}
+	}
+	for field, value := range beforeFields {
+		if _, ok := afterFields[field]; !ok {
+			diff[field] = models.AuditChange{Before: value}
+		}
+	}
+	return diff
+}
+
+func bookFields(book *models.Book) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if book == nil {
+		return fields
+	}
+	// The round trip leaves only JSON values, which hash the same after being
+	// read back from the database.
+	data, err := json.Marshal(book)
+	if err == nil {
+		_ = json.Unmarshal(data, &fields)
+	}
+	return fields
+}
+
+func jsonEqual(a, b interface{}) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
@@ -1,24 +1,52 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type (
 	Config struct {
-		Server        ServerConfig
-		Database      DatabaseConfig
-		Redis         RedisConfig
-		TableName     string
-		BookId        string
-		Title         string
-		Author        string
-		PublisherYear string
+		Server     ServerConfig
+		Database   DatabaseConfig
+		Redis      RedisConfig
+		Validation ValidationConfig
+		Trash      TrashConfig
+		Outbox     OutboxConfig
+		Webhooks   WebhookConfig
+		Events     EventsConfig
+		GraphQL    GraphQLConfig
+		API        APIConfig
+		TableName  string
+		// RevisionsTable shares the book column names of TableName.
+		RevisionsTable string
+		AuditTable     string
+		OutboxTable    string
+		// Webhook subscriptions and their delivery queue.
+		WebhooksTable   string
+		DeliveriesTable string
+		BookId          string
+		Title           string
+		Author          string
+		PublishedYear   string
+		SearchVector    string
+		Version         string
+		UpdatedAt       string
+		DeletedAt       string
 	}
 	ServerConfig struct {
 		Port string
+		// GRPCPort is where the gRPC API listens, next to the HTTP one.
+		GRPCPort    string
+		MaxPageSize int
+		// CursorSecret signs page cursors. Every replica must share it, since
+		// a cursor may come back to, or be cached by, any of them.
+		CursorSecret string
 	}
 	DatabaseConfig struct {
 		Host     string
@@ -28,8 +56,107 @@ type (
 		DBName   string
 	}
 	RedisConfig struct {
-		Host string
-		Port string
+		Host     string
+		Port     string
+		Username string
+		Password string
+		DB       int
+		// TLS, when set, verifies the server against TLSCAFile, or the system
+		// roots when that is empty.
+		TLS       bool
+		TLSCAFile string
+		// ConnectAttempts pings are made at startup, ConnectBackoff apart
+		// and doubling, before giving up on Redis.
+		ConnectAttempts int
+		ConnectBackoff  time.Duration
+		// The breaker opens after BreakerThreshold failures in a row and
+		// tries Redis again after BreakerCooldown.
+		BreakerThreshold int
+		BreakerCooldown  time.Duration
+		// KeyPrefix namespaces every key the service writes.
+		KeyPrefix string
+		BookTTL   time.Duration
+		// StaleGrace is how long past BookTTL a book is still served, while
+		// one request refreshes it in the background.
+		StaleGrace time.Duration
+		// LockTTL bounds how long one process holds the lock on refilling a
+		// book. The others wait up to LockWait for the refill before going
+		// to Postgres themselves.
+		LockTTL  time.Duration
+		LockWait time.Duration
+		// QueryTTL bounds how long a cached list or search result lives.
+		QueryTTL time.Duration
+		// LocalSize is how many books the in-process tier in front of Redis
+		// holds; LocalTTL bounds how stale a replica that missed an
+		// invalidation can be.
+		LocalSize int
+		LocalTTL  time.Duration
+		// DisableLocal and DisableShared switch off the in-process and the
+		// Redis tier, for debugging.
+		DisableLocal  bool
+		DisableShared bool
+	}
+	ValidationConfig struct {
+		MinPublishedYear int
+		// MaxPublishedYear of zero means "the current year".
+		MaxPublishedYear int
+		MaxTitleLength   int
+		MaxAuthorLength  int
+	}
+	TrashConfig struct {
+		Retention     time.Duration
+		PurgeInterval time.Duration
+	}
+	OutboxConfig struct {
+		// Stream is the Redis stream book events are published to, under
+		// Redis.KeyPrefix like every other key.
+		Stream       string
+		StreamMaxLen int64
+		PollInterval time.Duration
+		BatchSize    int
+		Lease        time.Duration
+		MaxBackoff   time.Duration
+		// Published events are deleted from the outbox after Retention,
+		// checked every SweepInterval.
+		Retention     time.Duration
+		SweepInterval time.Duration
+	}
+	EventsConfig struct {
+		// Channel is the Redis Pub/Sub channel live events are fanned out on,
+		// under Redis.KeyPrefix.
+		Channel      string
+		Heartbeat    time.Duration
+		ClientBuffer int
+	}
+	GraphQLConfig struct {
+		MaxComplexity int
+		MaxDepth      int
+		// PersistedQueryTTL is how long a registered query is remembered.
+		PersistedQueryTTL time.Duration
+		// PersistedQueryAllowlist, when set, is a JSON file mapping sha256
+		// hashes to queries. Only those queries run, and clients cannot
+		// register others.
+		PersistedQueryAllowlist string
+	}
+	APIConfig struct {
+		// Legacy is the unversioned routes, which serve v1.
+		Legacy Deprecation
+		V1     Deprecation
+	}
+	// Deprecation of an API version. A zero At means it is not deprecated.
+	Deprecation struct {
+		At time.Time
+		// Sunset, when set, is when the version is expected to go away.
+		Sunset time.Time
+	}
+	WebhookConfig struct {
+		PollInterval time.Duration
+		BatchSize    int
+		Lease        time.Duration
+		// A delivery goes to the dead-letter state after MaxAttempts failures.
+		MaxAttempts int
+		MaxBackoff  time.Duration
+		Timeout     time.Duration
 	}
 )
 
@@ -39,6 +166,12 @@ func (c *Config) Load() error {
 	}
 
 	c.Server.Port = ":" + os.Getenv("SERVER_PORT")
+	c.Server.GRPCPort = getEnv("GRPC_PORT", ":9090")
+	c.Server.MaxPageSize = getEnvInt("MAX_PAGE_SIZE", 100)
+	c.Server.CursorSecret = os.Getenv("CURSOR_SECRET")
+	if c.Server.CursorSecret == "" {
+		return errors.New("CURSOR_SECRET is required")
+	}
 	c.Database.Host = os.Getenv("DB_HOST")
 	c.Database.Port = os.Getenv("DB_PORT")
 	c.Database.User = os.Getenv("DB_USER")
@@ -46,15 +179,129 @@ func (c *Config) Load() error {
 	c.Database.DBName = os.Getenv("DB_NAME")
 	c.Redis.Host = os.Getenv("REDIS_HOST")
 	c.Redis.Port = os.Getenv("REDIS_PORT")
+	c.Redis.Username = os.Getenv("REDIS_USERNAME")
+	c.Redis.Password = os.Getenv("REDIS_PASSWORD")
+	c.Redis.DB = getEnvInt("REDIS_DB", 0)
+	c.Redis.TLS = getEnvBool("REDIS_TLS")
+	c.Redis.TLSCAFile = os.Getenv("REDIS_TLS_CA_FILE")
+	c.Redis.ConnectAttempts = getEnvInt("REDIS_CONNECT_ATTEMPTS", 5)
+	c.Redis.ConnectBackoff = getEnvDuration("REDIS_CONNECT_BACKOFF", time.Second)
+	c.Redis.BreakerThreshold = getEnvInt("REDIS_BREAKER_THRESHOLD", 5)
+	c.Redis.BreakerCooldown = getEnvDuration("REDIS_BREAKER_COOLDOWN", 10*time.Second)
+	c.Redis.KeyPrefix = getEnv("REDIS_KEY_PREFIX", "boock")
+	c.Redis.BookTTL = getEnvDuration("REDIS_BOOK_TTL", 24*time.Hour)
+	c.Redis.StaleGrace = getEnvDuration("REDIS_BOOK_STALE_GRACE", time.Minute)
+	c.Redis.LockTTL = getEnvDuration("REDIS_LOCK_TTL", 5*time.Second)
+	c.Redis.LockWait = getEnvDuration("REDIS_LOCK_WAIT", 500*time.Millisecond)
+	c.Redis.QueryTTL = getEnvDuration("REDIS_QUERY_TTL", 5*time.Minute)
+	c.Redis.LocalSize = getEnvInt("LOCAL_CACHE_SIZE", 10000)
+	c.Redis.LocalTTL = getEnvDuration("LOCAL_CACHE_TTL", 30*time.Second)
+	c.Redis.DisableLocal = getEnvBool("LOCAL_CACHE_DISABLED")
+	c.Redis.DisableShared = getEnvBool("REDIS_CACHE_DISABLED")
+	c.Validation.MinPublishedYear = getEnvInt("MIN_PUB_YEAR", 1)
+	c.Validation.MaxPublishedYear = getEnvInt("MAX_PUB_YEAR", 0)
+	c.Validation.MaxTitleLength = getEnvInt("MAX_TITLE_LENGTH", 255)
+	c.Validation.MaxAuthorLength = getEnvInt("MAX_AUTHOR_LENGTH", 255)
+	c.Trash.Retention = getEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+	c.Trash.PurgeInterval = getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour)
+	c.Outbox.Stream = getEnv("OUTBOX_STREAM", "books:events")
+	c.Outbox.StreamMaxLen = int64(getEnvInt("OUTBOX_STREAM_MAXLEN", 100000))
+	c.Outbox.PollInterval = getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second)
+	c.Outbox.BatchSize = getEnvInt("OUTBOX_BATCH_SIZE", 100)
+	c.Outbox.Lease = getEnvDuration("OUTBOX_LEASE", 30*time.Second)
+	c.Outbox.MaxBackoff = getEnvDuration("OUTBOX_MAX_BACKOFF", 5*time.Minute)
+	c.Outbox.Retention = getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour)
+	c.Outbox.SweepInterval = getEnvDuration("OUTBOX_SWEEP_INTERVAL", time.Hour)
+	c.Webhooks.PollInterval = getEnvDuration("WEBHOOK_POLL_INTERVAL", time.Second)
+	c.Webhooks.BatchSize = getEnvInt("WEBHOOK_BATCH_SIZE", 50)
+	c.Webhooks.Lease = getEnvDuration("WEBHOOK_LEASE", time.Minute)
+	c.Webhooks.MaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", 10)
+	c.Webhooks.MaxBackoff = getEnvDuration("WEBHOOK_MAX_BACKOFF", time.Hour)
+	c.Webhooks.Timeout = getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second)
+	c.Events.Channel = getEnv("EVENTS_CHANNEL", "books:events:live")
+	c.Events.Heartbeat = getEnvDuration("EVENTS_HEARTBEAT", 15*time.Second)
+	c.Events.ClientBuffer = getEnvInt("EVENTS_CLIENT_BUFFER", 64)
+	c.GraphQL.MaxComplexity = getEnvInt("GRAPHQL_MAX_COMPLEXITY", 5000)
+	c.GraphQL.MaxDepth = getEnvInt("GRAPHQL_MAX_DEPTH", 8)
+	c.GraphQL.PersistedQueryTTL = getEnvDuration("GRAPHQL_PERSISTED_QUERY_TTL", 7*24*time.Hour)
+	c.GraphQL.PersistedQueryAllowlist = os.Getenv("GRAPHQL_PERSISTED_QUERY_ALLOWLIST")
+	for key, target := range map[string]*time.Time{
+		"API_LEGACY_DEPRECATED_AT": &c.API.Legacy.At,
+		"API_LEGACY_SUNSET":        &c.API.Legacy.Sunset,
+		"API_V1_DEPRECATED_AT":     &c.API.V1.At,
+		"API_V1_SUNSET":            &c.API.V1.Sunset,
+	} {
+		t, err := getEnvTime(key)
+		if err != nil {
+			return err
+		}
+		*target = t
+	}
 	c.TableName = os.Getenv("TABLE_NAME")
+	c.RevisionsTable = os.Getenv("REVISIONS_TABLE_NAME")
+	c.AuditTable = os.Getenv("AUDIT_TABLE_NAME")
+	c.OutboxTable = os.Getenv("OUTBOX_TABLE_NAME")
+	c.WebhooksTable = os.Getenv("WEBHOOKS_TABLE_NAME")
+	c.DeliveriesTable = os.Getenv("WEBHOOK_DELIVERIES_TABLE_NAME")
 	c.BookId = os.Getenv("BOOK_ID")
 	c.Title = os.Getenv("TITLE")
 	c.Author = os.Getenv("AUTHOR")
-	c.PublisherYear = os.Getenv("PUB_YEAR")
+	c.PublishedYear = os.Getenv("PUB_YEAR")
+	c.SearchVector = os.Getenv("SEARCH_VECTOR")
+	c.Version = os.Getenv("BOOK_VERSION")
+	c.UpdatedAt = os.Getenv("BOOK_UPDATED_AT")
+	c.DeletedAt = os.Getenv("BOOK_DELETED_AT")
 
 	return nil
 }
 
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// getEnvBool is false unless key is set to something strconv.ParseBool
+// reads as true.
+func getEnvBool(key string) bool {
+	value, _ := strconv.ParseBool(os.Getenv(key))
+	return value
+}
+
+// getEnvTime reads an RFC 3339 timestamp or a plain date, which is taken as
+// midnight UTC. Unset is the zero time; anything else is an error, so a typo
+// does not quietly leave a version undeprecated.
+func getEnvTime(key string) (time.Time, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date, got %q", key, value)
+}
+
 func New() (*Config, error) {
 	var config Config
 	if err := config.Load(); err != nil {
@@ -0,0 +1,178 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/redisservice"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// replayPage is how many stream entries a resuming client is sent per read.
+const replayPage = 100
+
+// ErrGap means events a client asked to replay were trimmed from the stream.
+var ErrGap = errors.New("events after the given ID are no longer retained")
+
+type (
+	// Hub holds this replica's Redis Pub/Sub subscription to live events and
+	// fans every event out to the local listeners. Every replica runs its own
+	// hub, so every replica sees every event.
+	Hub struct {
+		redis     *redisservice.RedisService
+		stream    string
+		cfg       config.EventsConfig
+		logger    *log.Logger
+		mu        sync.Mutex
+		listeners map[chan *models.StreamedBookEvent]struct{}
+	}
+)
+
+func NewHub(redis *redisservice.RedisService, stream string, cfg config.EventsConfig, logger *log.Logger) *Hub {
+	return &Hub{
+		redis:     redis,
+		stream:    stream,
+		cfg:       cfg,
+		logger:    logger,
+		listeners: map[chan *models.StreamedBookEvent]struct{}{},
+	}
+}
+
+// Run relays announced events to the listeners until ctx is done. The Redis
+// client reconnects the subscription by itself.
+func (h *Hub) Run(ctx context.Context) {
+	pubsub := h.redis.SubscribeEvents(ctx, h.cfg.Channel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event models.StreamedBookEvent
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				h.logger.Println("Error decoding announced event:", err)
+				continue
+			}
+			h.broadcast(&event)
+		}
+	}
+}
+
+// broadcast never blocks on a slow listener: its channel is closed instead,
+// and the client reconnects with Last-Event-ID to catch up from the stream.
+func (h *Hub) broadcast(event *models.StreamedBookEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for listener := range h.listeners {
+		select {
+		case listener <- event:
+		default:
+			delete(h.listeners, listener)
+			close(listener)
+		}
+	}
+}
+
+// Heartbeat is how often an idle event stream should get a comment line, to
+// keep proxies from closing it.
+func (h *Hub) Heartbeat() time.Duration {
+	return h.cfg.Heartbeat
+}
+
+// Subscribe registers a listener. The returned function unregisters it and
+// must be called once the listener is done.
+func (h *Hub) Subscribe() (<-chan *models.StreamedBookEvent, func()) {
+	listener := make(chan *models.StreamedBookEvent, h.cfg.ClientBuffer)
+
+	h.mu.Lock()
+	h.listeners[listener] = struct{}{}
+	h.mu.Unlock()
+
+	return listener, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.listeners[listener]; ok {
+			delete(h.listeners, listener)
+			close(listener)
+		}
+	}
+}
+
+// Replay calls send with every event in the stream after afterId, oldest
+// first, and returns the ID of the last one sent. When the stream was
+// trimmed past afterId, the events the client missed are gone: it returns
+// ErrGap and the ID of the newest event, from which the client can follow
+// the stream once it has reloaded everything.
+func (h *Hub) Replay(ctx context.Context, afterId string, send func(*models.StreamedBookEvent) error) (string, error) {
+	first, last, err := h.redis.StreamBounds(ctx, h.stream)
+	if err != nil {
+		return afterId, err
+	}
+	// The oldest entry left may be the one right after afterId, which this
+	// cannot tell from a trimmed one, so it reports a gap then too. A
+	// needless reload beats a lost event.
+	if first != "" && After(first, afterId) {
+		return last, ErrGap
+	}
+
+	for {
+		page, err := h.redis.ReadEventsAfter(ctx, h.stream, afterId, replayPage)
+		if err != nil {
+			return afterId, err
+		}
+		for _, event := range page {
+			if err := send(event); err != nil {
+				return afterId, err
+			}
+			afterId = event.Id
+		}
+		if len(page) < replayPage {
+			return afterId, nil
+		}
+	}
+}
+
+// ValidId reports whether id looks like a Redis stream ID.
+func ValidId(id string) bool {
+	_, _, ok := parseId(id)
+	return ok
+}
+
+// After reports whether stream ID a comes after stream ID b.
+func After(a, b string) bool {
+	aMillis, aSeq, aOk := parseId(a)
+	bMillis, bSeq, bOk := parseId(b)
+	if !aOk || !bOk {
+		return a > b
+	}
+	return aMillis > bMillis || (aMillis == bMillis && aSeq > bSeq)
+}
+
+func parseId(id string) (uint64, uint64, bool) {
+	millis, seq, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	m, err := strconv.ParseUint(millis, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	s, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return m, s, true
+}
@@ -0,0 +1,197 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/redisservice"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+const (
+	testStream  = "books:events"
+	testChannel = "books:events:live"
+)
+
+func testHub(t *testing.T, clientBuffer int) (*Hub, *redisservice.RedisService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	logger := log.New(io.Discard, "", 0)
+	rs := redisservice.New(client, config.RedisConfig{KeyPrefix: "test", BreakerThreshold: 5, BreakerCooldown: time.Second}, logger)
+	hub := NewHub(rs, testStream, config.EventsConfig{Channel: testChannel, ClientBuffer: clientBuffer}, logger)
+	return hub, rs, mr
+}
+
+// publish appends n events to the stream, keeping at most maxLen, and
+// returns their IDs.
+func publish(t *testing.T, rs *redisservice.RedisService, n int, maxLen int64) []string {
+	t.Helper()
+	var ids []string
+	for i := 0; i < n; i++ {
+		id, err := rs.PublishEvent(context.Background(), testStream, testChannel, maxLen, &models.BookEvent{EventId: fmt.Sprint("e", i), Type: models.EventBookUpdated})
+		if err != nil {
+			t.Fatalf("PublishEvent: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func replay(t *testing.T, hub *Hub, afterId string) ([]string, string, error) {
+	t.Helper()
+	var sent []string
+	last, err := hub.Replay(context.Background(), afterId, func(event *models.StreamedBookEvent) error {
+		sent = append(sent, event.Id)
+		return nil
+	})
+	return sent, last, err
+}
+
+func TestReplay(t *testing.T) {
+	hub, rs, _ := testHub(t, 10)
+	// More than a page, so the replay takes several reads.
+	ids := publish(t, rs, replayPage+5, 1000)
+
+	tests := []struct {
+		name    string
+		afterId string
+		want    []string
+	}{
+		{"from the oldest", ids[0], ids[1:]},
+		{"from the middle", ids[replayPage], ids[replayPage+1:]},
+		{"from the newest", ids[len(ids)-1], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent, last, err := replay(t, hub, tt.afterId)
+			if err != nil {
+				t.Fatalf("Replay: %v", err)
+			}
+			if !reflect.DeepEqual(sent, tt.want) {
+				t.Fatalf("sent %d events, want %d", len(sent), len(tt.want))
+			}
+			if last != ids[len(ids)-1] {
+				t.Errorf("resumes after %s, want %s", last, ids[len(ids)-1])
+			}
+		})
+	}
+}
+
+func TestReplayEmptyStream(t *testing.T) {
+	hub, _, _ := testHub(t, 10)
+	sent, last, err := replay(t, hub, "1-0")
+	if err != nil || sent != nil || last != "1-0" {
+		t.Fatalf("got %v, %s, %v; want nothing to replay", sent, last, err)
+	}
+}
+
+func TestReplayAfterTrim(t *testing.T) {
+	hub, rs, _ := testHub(t, 10)
+	ids := publish(t, rs, 6, 3)
+	first, _, err := rs.StreamBounds(context.Background(), testStream)
+	if err != nil || first != ids[3] {
+		t.Fatalf("the stream starts at %s, %v; want it trimmed to %s", first, err, ids[3])
+	}
+
+	tests := []struct {
+		name    string
+		afterId string
+		wantGap bool
+	}{
+		{"older than the stream", ids[0], true},
+		// The entry right before the oldest one left cannot be told from a
+		// trimmed one.
+		{"right before the stream", ids[2], true},
+		{"inside the stream", ids[3], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent, last, err := replay(t, hub, tt.afterId)
+			if gap := errors.Is(err, ErrGap); gap != tt.wantGap {
+				t.Fatalf("got %v, want a gap: %v", err, tt.wantGap)
+			}
+			if tt.wantGap && sent != nil {
+				t.Errorf("sent %v before the reset", sent)
+			}
+			if last != ids[5] {
+				t.Errorf("resumes after %s, want %s", last, ids[5])
+			}
+		})
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub, rs, mr := testHub(t, 1)
+
+	fast, unsubscribeFast := hub.Subscribe()
+	defer unsubscribeFast()
+	other, unsubscribeOther := hub.Subscribe()
+	defer unsubscribeOther()
+	slow, unsubscribeSlow := hub.Subscribe()
+	defer unsubscribeSlow()
+
+	go hub.Run(ctx)
+	for deadline := time.Now().Add(time.Second); mr.PubSubNumSub("test:" + testChannel)["test:"+testChannel] == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the subscription")
+		}
+	}
+
+	receive := func(listener <-chan *models.StreamedBookEvent, want string) {
+		t.Helper()
+		select {
+		case event, ok := <-listener:
+			if !ok || event.Id != want {
+				t.Fatalf("got %+v, %v; want %s", event, ok, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+
+	ids := publish(t, rs, 1, 1000)
+	receive(fast, ids[0])
+	receive(other, ids[0])
+
+	// The slow listener's buffer is still full with the first event, so it
+	// is dropped rather than holding up the others.
+	ids = append(ids, publish(t, rs, 1, 1000)...)
+	receive(fast, ids[1])
+	receive(other, ids[1])
+	receive(slow, ids[0])
+	if _, ok := <-slow; ok {
+		t.Fatal("the slow listener was not dropped")
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2-0", "1-5", true},
+		{"1-5", "2-0", false},
+		{"1-10", "1-9", true},
+		{"1-9", "1-10", false},
+		{"1-0", "1-0", false},
+		{"10-0", "9-0", true},
+	}
+	for _, tt := range tests {
+		if got := After(tt.a, tt.b); got != tt.want {
+			t.Errorf("After(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
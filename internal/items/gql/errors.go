@@ -0,0 +1,79 @@
+package gql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+)
+
+// Error codes sent in the extensions of an error, next to the message.
+const (
+	codeInternal               = "INTERNAL"
+	codeBadRequest             = "BAD_REQUEST"
+	codeNotFound               = "NOT_FOUND"
+	codeConflict               = "CONFLICT"
+	codeValidation             = "VALIDATION_FAILED"
+	codeUnavailable            = "UNAVAILABLE"
+	codePreconditionFailed     = "PRECONDITION_FAILED"
+	codeTooComplex             = "QUERY_TOO_COMPLEX"
+	codePersistedQueryNotFound = "PERSISTED_QUERY_NOT_FOUND"
+	codeNotAllowlisted         = "PERSISTED_QUERY_NOT_ALLOWED"
+)
+
+var codeByKind = map[apperrors.Kind]string{
+	apperrors.Internal:           codeInternal,
+	apperrors.Invalid:            codeBadRequest,
+	apperrors.NotFound:           codeNotFound,
+	apperrors.Conflict:           codeConflict,
+	apperrors.Validation:         codeValidation,
+	apperrors.Unavailable:        codeUnavailable,
+	apperrors.PreconditionFailed: codePreconditionFailed,
+}
+
+// extendedError carries the code of an application error, and its field
+// details, into the GraphQL error.
+type extendedError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *extendedError) Error() string {
+	return e.message
+}
+
+func (e *extendedError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+// resolverError is what a resolver returns for err. Internal errors are
+// reported without their text, as in the HTTP API.
+func resolverError(err error) error {
+	if err == nil {
+		return nil
+	}
+	code := codeByKind[apperrors.KindOf(err)]
+
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || code == codeInternal {
+		return &extendedError{message: "internal error", extensions: map[string]interface{}{"code": codeInternal}}
+	}
+	extensions := map[string]interface{}{"code": code}
+	if len(appErr.Details) > 0 {
+		extensions["details"] = appErr.Details
+	}
+	return &extendedError{message: appErr.Message, extensions: extensions}
+}
+
+// requestError is a result for a request that was turned down before it ran.
+func requestError(code, message string) *graphql.Result {
+	return &graphql.Result{
+		Errors: []gqlerrors.FormattedError{{
+			Message:    message,
+			Locations:  []location.SourceLocation{},
+			Extensions: map[string]interface{}{"code": code},
+		}},
+	}
+}
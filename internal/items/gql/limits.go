@@ -0,0 +1,110 @@
+package gql
+
+import (
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListLimit is the page size of a list that does not ask for one.
+const defaultListLimit = 10
+
+// listFields are the root fields that return up to limit items, so whatever
+// they select costs limit times as much.
+var listFields = map[string]bool{
+	"books":         true,
+	"booksByAuthor": true,
+	"booksByTitle":  true,
+	"trash":         true,
+	"search":        true,
+}
+
+type measure struct {
+	fragments   map[string]*ast.FragmentDefinition
+	variables   map[string]interface{}
+	defaults    map[string]ast.Value
+	maxPageSize int
+}
+
+// cost returns the complexity and depth of operation. Every field costs one,
+// and the fields below a list cost once per item the list may return. The
+// document must already be valid, so fragments cannot form cycles.
+func (m *measure) cost(operation *ast.OperationDefinition) (complexity, depth int) {
+	m.defaults = map[string]ast.Value{}
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			m.defaults[definition.Variable.Name.Value] = definition.DefaultValue
+		}
+	}
+	return m.selectionSet(operation.SelectionSet, 0)
+}
+
+func (m *measure) selectionSet(set *ast.SelectionSet, depth int) (int, int) {
+	if set == nil {
+		return 0, depth
+	}
+	complexity, maxDepth := 0, depth
+	for _, selection := range set.Selections {
+		var c, d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c, d = m.selectionSet(selection.SelectionSet, depth+1)
+			if depth == 0 {
+				c *= m.multiplier(selection)
+			}
+			c++
+			if d < depth+1 {
+				d = depth + 1
+			}
+		case *ast.InlineFragment:
+			c, d = m.selectionSet(selection.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			if fragment := m.fragments[selection.Name.Value]; fragment != nil {
+				c, d = m.selectionSet(fragment.SelectionSet, depth)
+			}
+		}
+		complexity += c
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+	return complexity, maxDepth
+}
+
+// multiplier is the number of items field can return, as far as the query
+// says: its limit argument, clamped the way the storage clamps it.
+func (m *measure) multiplier(field *ast.Field) int {
+	if !listFields[field.Name.Value] {
+		return 1
+	}
+	limit := defaultListLimit
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		value := argument.Value
+		if variable, ok := value.(*ast.Variable); ok {
+			switch n := m.variables[variable.Name.Value].(type) {
+			case float64:
+				limit = int(n)
+				continue
+			case int:
+				limit = n
+				continue
+			}
+			value = m.defaults[variable.Name.Value]
+		}
+		if literal, ok := value.(*ast.IntValue); ok {
+			if n, err := strconv.Atoi(literal.Value); err == nil {
+				limit = n
+			}
+		}
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > m.maxPageSize {
+		limit = m.maxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,285 @@
+package gql
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+var bookType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Book",
+	Fields: graphql.Fields{
+		"bookId":        bookField(graphql.NewNonNull(graphql.ID), func(b *models.Book) interface{} { return b.BookId }),
+		"title":         bookField(graphql.NewNonNull(graphql.String), func(b *models.Book) interface{} { return b.Title }),
+		"author":        bookField(graphql.NewNonNull(graphql.String), func(b *models.Book) interface{} { return b.Author }),
+		"publishedYear": bookField(graphql.NewNonNull(graphql.Int), func(b *models.Book) interface{} { return b.PublishedYear }),
+		"version":       bookField(graphql.NewNonNull(graphql.Int), func(b *models.Book) interface{} { return b.Version }),
+		"updatedAt":     bookField(graphql.NewNonNull(graphql.DateTime), func(b *models.Book) interface{} { return b.UpdatedAt }),
+		"deletedAt":     bookField(graphql.DateTime, func(b *models.Book) interface{} { return b.DeletedAt }),
+	},
+})
+
+var paginationType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Pagination",
+	Fields: graphql.Fields{
+		"total":      paginationField(graphql.Int, func(p *models.Pagination) interface{} { return p.Total }),
+		"page":       paginationField(graphql.Int, func(p *models.Pagination) interface{} { return p.Page }),
+		"limit":      paginationField(graphql.NewNonNull(graphql.Int), func(p *models.Pagination) interface{} { return p.Limit }),
+		"hasNext":    paginationField(graphql.NewNonNull(graphql.Boolean), func(p *models.Pagination) interface{} { return p.HasNext }),
+		"nextCursor": paginationField(graphql.String, func(p *models.Pagination) interface{} { return p.NextCursor }),
+		"prevCursor": paginationField(graphql.String, func(p *models.Pagination) interface{} { return p.PrevCursor }),
+	},
+})
+
+var bookPageType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BookPage",
+	Fields: graphql.Fields{
+		"books": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookType))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.GetSeveralResponse).Books, nil
+			},
+		},
+		"pagination": &graphql.Field{
+			Type: paginationType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.GetSeveralResponse).Pagination, nil
+			},
+		},
+	},
+})
+
+var searchResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "SearchResult",
+	Fields: graphql.Fields{
+		"book": &graphql.Field{
+			Type: graphql.NewNonNull(bookType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.SearchResult).Book, nil
+			},
+		},
+		"rank": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Float),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.SearchResult).Rank, nil
+			},
+		},
+		"titleHighlight": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.SearchResult).Highlights.Title, nil
+			},
+		},
+		"authorHighlight": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.SearchResult).Highlights.Author, nil
+			},
+		},
+	},
+})
+
+var bookInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "BookInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"title":         &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"author":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"publishedYear": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+	},
+})
+
+// pagingArgs are the arguments every paged list takes, as in the HTTP API.
+func pagingArgs(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args["page"] = &graphql.ArgumentConfig{Type: graphql.Int}
+	args["limit"] = &graphql.ArgumentConfig{Type: graphql.Int}
+	args["cursor"] = &graphql.ArgumentConfig{Type: graphql.String}
+	return args
+}
+
+func newSchema(service repository.IBookRepo) (graphql.Schema, error) {
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"book": &graphql.Field{
+				Type: bookType,
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"asOf": &graphql.ArgumentConfig{Type: graphql.DateTime},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := &models.GetBookByIdRequest{BookId: stringArg(p, "id")}
+					if asOf, ok := p.Args["asOf"].(time.Time); ok {
+						req.AsOf = asOf
+					}
+					book, err := service.GetBookById(p.Context, req)
+					return book, resolverError(err)
+				},
+			},
+			"books": &graphql.Field{
+				Type: graphql.NewNonNull(bookPageType),
+				Args: pagingArgs(graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: graphql.String},
+					"sort":   &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					response, err := service.GetAllBooks(p.Context, &models.GetAllBooksRequest{
+						Filter: stringArg(p, "filter"),
+						Sort:   stringArg(p, "sort"),
+						Paging: paging(p),
+					})
+					return response, resolverError(err)
+				},
+			},
+			"booksByAuthor": &graphql.Field{
+				Type: graphql.NewNonNull(bookPageType),
+				Args: pagingArgs(graphql.FieldConfigArgument{
+					"author": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					response, err := service.GetBooksByAuthor(p.Context, &models.GetBooksByAuthorRequest{
+						Author: stringArg(p, "author"),
+						Paging: paging(p),
+					})
+					return response, resolverError(err)
+				},
+			},
+			"booksByTitle": &graphql.Field{
+				Type: graphql.NewNonNull(bookPageType),
+				Args: pagingArgs(graphql.FieldConfigArgument{
+					"title": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					response, err := service.GetBooksByName(p.Context, &models.GetBooksByNameRequest{
+						BookName: stringArg(p, "title"),
+						Paging:   paging(p),
+					})
+					return response, resolverError(err)
+				},
+			},
+			"trash": &graphql.Field{
+				Type: graphql.NewNonNull(bookPageType),
+				Args: pagingArgs(graphql.FieldConfigArgument{}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					response, err := service.ListTrash(p.Context, &models.ListTrashRequest{Paging: paging(p)})
+					return response, resolverError(err)
+				},
+			},
+			"search": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(searchResultType))),
+				Args: graphql.FieldConfigArgument{
+					"query": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultListLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					response, err := service.SearchBooks(p.Context, &models.SearchBooksRequest{
+						Search: stringArg(p, "query"),
+						Limit:  intArg(p, "limit"),
+					})
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					return response.Results, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createBook": &graphql.Field{
+				Type: graphql.NewNonNull(bookType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					input := p.Args["input"].(map[string]interface{})
+					book, err := service.CreateBook(p.Context, &models.CreateBookRequest{
+						Title:         input["title"].(string),
+						Author:        input["author"].(string),
+						PublishedYear: input["publishedYear"].(int),
+					})
+					return book, resolverError(err)
+				},
+			},
+			"updateBook": &graphql.Field{
+				Type:        graphql.NewNonNull(bookType),
+				Description: "Replaces a book. expectedVersion plays the part of If-Match.",
+				Args: graphql.FieldConfigArgument{
+					"id":              &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"expectedVersion": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input":           &graphql.ArgumentConfig{Type: graphql.NewNonNull(bookInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					input := p.Args["input"].(map[string]interface{})
+					book, err := service.UpdateBook(p.Context, &models.UpdateBookRequest{
+						BookId:          stringArg(p, "id"),
+						Title:           input["title"].(string),
+						Author:          input["author"].(string),
+						PublishedYear:   input["publishedYear"].(int),
+						ExpectedVersion: int64(intArg(p, "expectedVersion")),
+					})
+					return book, resolverError(err)
+				},
+			},
+			"deleteBook": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Moves a book to the trash. expectedVersion plays the part of If-Match.",
+				Args: graphql.FieldConfigArgument{
+					"id":              &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"expectedVersion": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					err := service.DeleteBookById(p.Context, &models.DeleteBookByIdRequest{
+						BookId:          stringArg(p, "id"),
+						ExpectedVersion: int64(intArg(p, "expectedVersion")),
+					})
+					return err == nil, resolverError(err)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func bookField(fieldType graphql.Output, value func(*models.Book) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return value(p.Source.(*models.Book)), nil
+		},
+	}
+}
+
+func paginationField(fieldType graphql.Output, value func(*models.Pagination) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return value(p.Source.(*models.Pagination)), nil
+		},
+	}
+}
+
+func stringArg(p graphql.ResolveParams, name string) string {
+	value, _ := p.Args[name].(string)
+	return value
+}
+
+func intArg(p graphql.ResolveParams, name string) int {
+	value, _ := p.Args[name].(int)
+	return value
+}
+
+func paging(p graphql.ResolveParams) models.Paging {
+	return models.Paging{
+		Page:   intArg(p, "page"),
+		Limit:  intArg(p, "limit"),
+		Cursor: stringArg(p, "cursor"),
+	}
+}
@@ -0,0 +1,218 @@
+package gql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/redisservice"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// Server runs GraphQL requests against the book service. Every request is
+	// checked against the complexity and depth limits before it runs.
+	Server struct {
+		schema      graphql.Schema
+		redis       *redisservice.RedisService
+		cfg         config.GraphQLConfig
+		maxPageSize int
+		// allowlist holds the only queries that run, by hash, when the
+		// server is configured with one.
+		allowlist map[string]string
+		logger    *log.Logger
+	}
+)
+
+func New(service repository.IBookRepo, redis *redisservice.RedisService, cfg config.GraphQLConfig, maxPageSize int, logger *log.Logger) (*Server, error) {
+	schema, err := newSchema(service)
+	if err != nil {
+		return nil, err
+	}
+	var allowlist map[string]string
+	if cfg.PersistedQueryAllowlist != "" {
+		if allowlist, err = loadAllowlist(cfg.PersistedQueryAllowlist); err != nil {
+			return nil, err
+		}
+		logger.Printf("GraphQL runs only the %d allowlisted queries\n", len(allowlist))
+	}
+	return &Server{
+		schema:      schema,
+		redis:       redis,
+		cfg:         cfg,
+		maxPageSize: maxPageSize,
+		allowlist:   allowlist,
+		logger:      logger,
+	}, nil
+}
+
+// loadAllowlist reads a JSON object of sha256 hashes to queries, checking
+// that every query has the hash it is listed under.
+func loadAllowlist(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var allowlist map[string]string
+	if err := json.Unmarshal(data, &allowlist); err != nil {
+		return nil, fmt.Errorf("persisted query allowlist %s: %w", path, err)
+	}
+	for hash, query := range allowlist {
+		if sha256Hex(query) != hash {
+			return nil, fmt.Errorf("persisted query allowlist %s: query listed under %s has a different sha256", path, hash)
+		}
+	}
+	return allowlist, nil
+}
+
+func sha256Hex(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Execute runs req. With queryOnly set, as for GET requests, mutations are
+// refused. Problems with the request itself are reported in the result, the
+// way GraphQL clients expect them.
+func (s *Server) Execute(ctx context.Context, req *models.GraphQLRequest, queryOnly bool) *graphql.Result {
+	query, persist, result := s.resolveQuery(ctx, req)
+	if result != nil {
+		return result
+	}
+
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := graphql.ValidateDocument(&s.schema, document, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	operation := findOperation(document, req.OperationName)
+	if operation == nil && req.OperationName == "" {
+		return requestError(codeBadRequest, "operationName is required when the document has several operations")
+	} else if operation == nil {
+		return requestError(codeBadRequest, "Unknown operation "+req.OperationName)
+	}
+	if queryOnly && operation.Operation != ast.OperationTypeQuery {
+		return requestError(codeBadRequest, "Only queries can be sent with GET")
+	}
+
+	m := &measure{
+		fragments:   fragments(document),
+		variables:   req.Variables,
+		maxPageSize: s.maxPageSize,
+	}
+	complexity, depth := m.cost(operation)
+	if depth > s.cfg.MaxDepth {
+		return requestError(codeTooComplex, fmt.Sprintf("Query depth %d exceeds the limit of %d", depth, s.cfg.MaxDepth))
+	}
+	if complexity > s.cfg.MaxComplexity {
+		return requestError(codeTooComplex, fmt.Sprintf("Query complexity %d exceeds the limit of %d", complexity, s.cfg.MaxComplexity))
+	}
+
+	// Only queries that passed the limits are remembered.
+	if persist != "" {
+		if err := s.redis.StorePersistedQuery(ctx, persist, query, s.cfg.PersistedQueryTTL); err != nil {
+			s.logger.Println("Error storing persisted query:", err)
+		}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// resolveQuery implements automatic persisted queries: a client may send only
+// the sha256 of a query, and sends the full text once the server reports it
+// does not know the hash. It returns the hash to store the query under when
+// the request registers one. With an allowlist only its queries run, looked
+// up by hash, and nothing is registered.
+func (s *Server) resolveQuery(ctx context.Context, req *models.GraphQLRequest) (string, string, *graphql.Result) {
+	persisted := req.Extensions.PersistedQuery
+	if s.allowlist != nil {
+		if persisted == nil {
+			return "", "", requestError(codeNotAllowlisted, "Only persisted queries are allowed")
+		}
+		if persisted.Version != 1 {
+			return "", "", requestError(codeBadRequest, "Unsupported persisted query version")
+		}
+		query, ok := s.allowlist[persisted.Sha256Hash]
+		if !ok {
+			return "", "", requestError(codeNotAllowlisted, "Persisted query is not allowlisted")
+		}
+		return query, "", nil
+	}
+	if persisted == nil {
+		if req.Query == "" {
+			return "", "", requestError(codeBadRequest, "Query is required")
+		}
+		return req.Query, "", nil
+	}
+	if persisted.Version != 1 {
+		return "", "", requestError(codeBadRequest, "Unsupported persisted query version")
+	}
+
+	if req.Query == "" {
+		// Without Redis a hash is unknown, and the client sends the query.
+		query, err := s.redis.GetPersistedQuery(ctx, persisted.Sha256Hash)
+		if err != nil {
+			s.logger.Println("Error loading persisted query:", err)
+		}
+		if query == "" {
+			return "", "", requestError(codePersistedQueryNotFound, "PersistedQueryNotFound")
+		}
+		return query, "", nil
+	}
+
+	if sha256Hex(req.Query) != persisted.Sha256Hash {
+		return "", "", requestError(codeBadRequest, "Provided sha256Hash does not match the query")
+	}
+	return req.Query, persisted.Sha256Hash, nil
+}
+
+func findOperation(document *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				// Several operations need a name to pick one.
+				return nil
+			}
+			found = operation
+		} else if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
+
+func fragments(document *ast.Document) map[string]*ast.FragmentDefinition {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+	return fragments
+}
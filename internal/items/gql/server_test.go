@@ -0,0 +1,81 @@
+package gql
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+const typenameQuery = "{ __typename }"
+
+func allowlistServer(t *testing.T) *Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "allowlist.json")
+	content := `{"` + sha256Hex(typenameQuery) + `": "` + typenameQuery + `"}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.GraphQLConfig{MaxComplexity: 100, MaxDepth: 5, PersistedQueryAllowlist: path}
+	server, err := New(nil, nil, cfg, 100, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return server
+}
+
+func errorCode(result *graphql.Result) interface{} {
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	return result.Errors[0].Extensions["code"]
+}
+
+func TestAllowlistRunsOnlyListedQueries(t *testing.T) {
+	server := allowlistServer(t)
+	persisted := func(hash string) models.GraphQLExtensions {
+		return models.GraphQLExtensions{PersistedQuery: &models.PersistedQuery{Version: 1, Sha256Hash: hash}}
+	}
+	other := "{ __schema { queryType { name } } }"
+
+	tests := []struct {
+		name string
+		req  models.GraphQLRequest
+		want interface{}
+	}{
+		{"listed hash", models.GraphQLRequest{Extensions: persisted(sha256Hex(typenameQuery))}, nil},
+		{"plain query", models.GraphQLRequest{Query: typenameQuery}, codeNotAllowlisted},
+		{"unlisted hash", models.GraphQLRequest{Extensions: persisted(sha256Hex(other))}, codeNotAllowlisted},
+		// Sending the text, as automatic persisted queries do, registers nothing.
+		{"registration", models.GraphQLRequest{Query: other, Extensions: persisted(sha256Hex(other))}, codeNotAllowlisted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := server.Execute(context.Background(), &tt.req, true)
+			if got := errorCode(result); got != tt.want {
+				t.Fatalf("got code %v, want %v: %v", got, tt.want, result.Errors)
+			}
+			if tt.want == nil && result.Data.(map[string]interface{})["__typename"] != "Query" {
+				t.Errorf("got data %v", result.Data)
+			}
+		})
+	}
+}
+
+func TestAllowlistRejectsMislabelledQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist.json")
+	content := `{"` + sha256Hex("{ books { total } }") + `": "` + typenameQuery + `"}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.GraphQLConfig{PersistedQueryAllowlist: path}
+	if _, err := New(nil, nil, cfg, 100, log.New(io.Discard, "", 0)); err == nil {
+		t.Fatal("New accepted a query listed under another hash")
+	}
+}
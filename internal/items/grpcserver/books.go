@@ -0,0 +1,276 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/models"
+	bookv1 "github.com/ruziba3vich/boock/internal/pkg/pb/book/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultSearchLimit matches the default of the HTTP search endpoint.
+const defaultSearchLimit = 10
+
+func (s *Server) CreateBook(ctx context.Context, req *bookv1.CreateBookRequest) (*bookv1.Book, error) {
+	book, err := s.service.CreateBook(ctx, &models.CreateBookRequest{
+		Title:         req.GetTitle(),
+		Author:        req.GetAuthor(),
+		PublishedYear: int(req.GetPublishedYear()),
+	})
+	if err != nil {
+		s.logger.Println("Error creating book:", err)
+		return nil, statusError(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *Server) UpdateBook(ctx context.Context, req *bookv1.UpdateBookRequest) (*bookv1.Book, error) {
+	if err := requireVersion(req.GetExpectedVersion()); err != nil {
+		return nil, err
+	}
+	book, err := s.service.UpdateBook(ctx, &models.UpdateBookRequest{
+		BookId:          req.GetBookId(),
+		Title:           req.GetTitle(),
+		Author:          req.GetAuthor(),
+		PublishedYear:   int(req.GetPublishedYear()),
+		ExpectedVersion: req.GetExpectedVersion(),
+	})
+	if err != nil {
+		s.logger.Println("Error updating book:", err)
+		return nil, statusError(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *Server) PatchBook(ctx context.Context, req *bookv1.PatchBookRequest) (*bookv1.Book, error) {
+	patchType := req.GetPatchType()
+	if patchType != models.MergePatchType && patchType != models.JSONPatchType {
+		return nil, invalid("patch_type must be %s or %s", models.MergePatchType, models.JSONPatchType)
+	}
+	if err := requireVersion(req.GetExpectedVersion()); err != nil {
+		return nil, err
+	}
+	if len(req.GetPatch()) == 0 {
+		return nil, invalid("patch is required")
+	}
+	book, err := s.service.PatchBook(ctx, &models.PatchBookRequest{
+		BookId:          req.GetBookId(),
+		PatchType:       patchType,
+		Patch:           req.GetPatch(),
+		ExpectedVersion: req.GetExpectedVersion(),
+	})
+	if err != nil {
+		s.logger.Println("Error patching book:", err)
+		return nil, statusError(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *Server) GetBook(ctx context.Context, req *bookv1.GetBookRequest) (*bookv1.Book, error) {
+	getReq := &models.GetBookByIdRequest{BookId: req.GetBookId()}
+	if req.AsOf != nil {
+		if err := req.AsOf.CheckValid(); err != nil {
+			return nil, invalid("invalid as_of: %s", err.Error())
+		}
+		getReq.AsOf = req.AsOf.AsTime()
+	}
+	book, err := s.service.GetBookById(ctx, getReq)
+	if err != nil {
+		s.logger.Println("Error getting book:", err)
+		return nil, statusError(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *Server) DeleteBook(ctx context.Context, req *bookv1.DeleteBookRequest) (*emptypb.Empty, error) {
+	if err := requireVersion(req.GetExpectedVersion()); err != nil {
+		return nil, err
+	}
+	err := s.service.DeleteBookById(ctx, &models.DeleteBookByIdRequest{
+		BookId:          req.GetBookId(),
+		ExpectedVersion: req.GetExpectedVersion(),
+	})
+	if err != nil {
+		s.logger.Println("Error deleting book:", err)
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) RestoreBook(ctx context.Context, req *bookv1.RestoreBookRequest) (*bookv1.Book, error) {
+	book, err := s.service.RestoreBook(ctx, &models.RestoreBookRequest{BookId: req.GetBookId()})
+	if err != nil {
+		s.logger.Println("Error restoring book:", err)
+		return nil, statusError(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *Server) RevertBook(ctx context.Context, req *bookv1.RevertBookRequest) (*bookv1.Book, error) {
+	if req.GetRevision() <= 0 {
+		return nil, invalid("revision must be a positive number")
+	}
+	if err := requireVersion(req.GetExpectedVersion()); err != nil {
+		return nil, err
+	}
+	book, err := s.service.RevertBook(ctx, &models.RevertBookRequest{
+		BookId:          req.GetBookId(),
+		Revision:        req.GetRevision(),
+		ExpectedVersion: req.GetExpectedVersion(),
+	})
+	if err != nil {
+		s.logger.Println("Error reverting book:", err)
+		return nil, statusError(err)
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *Server) PurgeDeletedBooks(ctx context.Context, req *bookv1.PurgeDeletedBooksRequest) (*bookv1.PurgeDeletedBooksResponse, error) {
+	if req.DeletedBefore == nil {
+		return nil, invalid("deleted_before is required")
+	}
+	if err := req.DeletedBefore.CheckValid(); err != nil {
+		return nil, invalid("invalid deleted_before: %s", err.Error())
+	}
+	// A caller may empty the trash sooner than the purger does, but only of
+	// books that are past the retention window already.
+	deletedBefore := req.DeletedBefore.AsTime()
+	if latest := s.now().Add(-s.trashRetention); deletedBefore.After(latest) {
+		return nil, invalid("deleted_before must not be after %s, the end of the trash retention window", latest.UTC().Format(time.RFC3339))
+	}
+	response, err := s.service.PurgeDeletedBooks(ctx, &models.PurgeDeletedBooksRequest{
+		DeletedBefore: deletedBefore,
+	})
+	if err != nil {
+		s.logger.Println("Error purging deleted books:", err)
+		return nil, statusError(err)
+	}
+	return &bookv1.PurgeDeletedBooksResponse{Purged: response.Purged}, nil
+}
+
+func (s *Server) GetCatalogVersion(ctx context.Context, _ *emptypb.Empty) (*bookv1.CatalogVersion, error) {
+	version, err := s.service.GetCatalogVersion(ctx)
+	if err != nil {
+		s.logger.Println("Error getting catalog version:", err)
+		return nil, statusError(err)
+	}
+	return &bookv1.CatalogVersion{ModifiedAt: timestamppb.New(version.ModifiedAt)}, nil
+}
+
+func (s *Server) ListBooks(req *bookv1.ListBooksRequest, stream grpc.ServerStreamingServer[bookv1.ListBooksResponse]) error {
+	response, err := s.service.GetAllBooks(stream.Context(), &models.GetAllBooksRequest{
+		Filter: req.GetFilter(),
+		Sort:   req.GetSort(),
+		Paging: toPaging(req.GetPaging()),
+	})
+	if err != nil {
+		s.logger.Println("Error getting all books:", err)
+		return statusError(err)
+	}
+	return sendBooks(stream, response)
+}
+
+func (s *Server) ListBooksByAuthor(req *bookv1.ListBooksByAuthorRequest, stream grpc.ServerStreamingServer[bookv1.ListBooksResponse]) error {
+	if req.GetAuthor() == "" {
+		return invalid("author is required")
+	}
+	response, err := s.service.GetBooksByAuthor(stream.Context(), &models.GetBooksByAuthorRequest{
+		Author: req.GetAuthor(),
+		Paging: toPaging(req.GetPaging()),
+	})
+	if err != nil {
+		s.logger.Println("Error getting books by author:", err)
+		return statusError(err)
+	}
+	return sendBooks(stream, response)
+}
+
+func (s *Server) ListBooksByTitle(req *bookv1.ListBooksByTitleRequest, stream grpc.ServerStreamingServer[bookv1.ListBooksResponse]) error {
+	if req.GetTitle() == "" {
+		return invalid("title is required")
+	}
+	response, err := s.service.GetBooksByName(stream.Context(), &models.GetBooksByNameRequest{
+		BookName: req.GetTitle(),
+		Paging:   toPaging(req.GetPaging()),
+	})
+	if err != nil {
+		s.logger.Println("Error getting books by name:", err)
+		return statusError(err)
+	}
+	return sendBooks(stream, response)
+}
+
+func (s *Server) ListTrash(req *bookv1.ListTrashRequest, stream grpc.ServerStreamingServer[bookv1.ListBooksResponse]) error {
+	response, err := s.service.ListTrash(stream.Context(), &models.ListTrashRequest{
+		Paging: toPaging(req.GetPaging()),
+	})
+	if err != nil {
+		s.logger.Println("Error listing trash:", err)
+		return statusError(err)
+	}
+	return sendBooks(stream, response)
+}
+
+func (s *Server) SearchBooks(req *bookv1.SearchBooksRequest, stream grpc.ServerStreamingServer[bookv1.SearchResult]) error {
+	if req.GetSearch() == "" {
+		return invalid("search is required")
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	response, err := s.service.SearchBooks(stream.Context(), &models.SearchBooksRequest{
+		Search: req.GetSearch(),
+		Limit:  limit,
+	})
+	if err != nil {
+		s.logger.Println("Error searching books:", err)
+		return statusError(err)
+	}
+	for _, result := range response.Results {
+		if err := stream.Send(toProtoSearchResult(result)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetBookHistory(req *bookv1.GetBookHistoryRequest, stream grpc.ServerStreamingServer[bookv1.GetBookHistoryResponse]) error {
+	response, err := s.service.GetBookHistory(stream.Context(), &models.GetBookHistoryRequest{
+		BookId: req.GetBookId(),
+		Paging: toPaging(req.GetPaging()),
+	})
+	if err != nil {
+		s.logger.Println("Error getting book history:", err)
+		return statusError(err)
+	}
+	for _, revision := range response.Revisions {
+		item := &bookv1.GetBookHistoryResponse_Revision{Revision: toProtoRevision(revision)}
+		if err := stream.Send(&bookv1.GetBookHistoryResponse{Item: item}); err != nil {
+			return err
+		}
+	}
+	if response.Pagination == nil {
+		return nil
+	}
+	item := &bookv1.GetBookHistoryResponse_Pagination{Pagination: toProtoPagination(response.Pagination)}
+	return stream.Send(&bookv1.GetBookHistoryResponse{Item: item})
+}
+
+// sendBooks streams one message per book and ends with the pagination.
+func sendBooks(stream grpc.ServerStreamingServer[bookv1.ListBooksResponse], response *models.GetSeveralResponse) error {
+	for _, book := range response.Books {
+		item := &bookv1.ListBooksResponse_Book{Book: toProtoBook(book)}
+		if err := stream.Send(&bookv1.ListBooksResponse{Item: item}); err != nil {
+			return err
+		}
+	}
+	if response.Pagination == nil {
+		return nil
+	}
+	item := &bookv1.ListBooksResponse_Pagination{Pagination: toProtoPagination(response.Pagination)}
+	return stream.Send(&bookv1.ListBooksResponse{Item: item})
+}
@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"github.com/ruziba3vich/boock/internal/models"
+	bookv1 "github.com/ruziba3vich/boock/internal/pkg/pb/book/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoBook(book *models.Book) *bookv1.Book {
+	if book == nil {
+		return nil
+	}
+	pb := &bookv1.Book{
+		BookId:        book.BookId,
+		Title:         book.Title,
+		Author:        book.Author,
+		PublishedYear: int32(book.PublishedYear),
+		Version:       book.Version,
+		UpdatedAt:     timestamppb.New(book.UpdatedAt),
+	}
+	if book.DeletedAt != nil {
+		pb.DeletedAt = timestamppb.New(*book.DeletedAt)
+	}
+	return pb
+}
+
+func toProtoRevision(revision *models.BookRevision) *bookv1.BookRevision {
+	return &bookv1.BookRevision{
+		Revision:   revision.Revision,
+		Operation:  revision.Operation,
+		ChangedBy:  revision.ChangedBy,
+		RecordedAt: timestamppb.New(revision.RecordedAt),
+		Book:       toProtoBook(revision.Book),
+	}
+}
+
+func toProtoSearchResult(result *models.SearchResult) *bookv1.SearchResult {
+	return &bookv1.SearchResult{
+		Book: toProtoBook(result.Book),
+		Rank: result.Rank,
+		Highlights: &bookv1.SearchHighlights{
+			Title:  result.Highlights.Title,
+			Author: result.Highlights.Author,
+		},
+	}
+}
+
+func toProtoPagination(pagination *models.Pagination) *bookv1.Pagination {
+	return &bookv1.Pagination{
+		Total:      pagination.Total,
+		Page:       int32(pagination.Page),
+		Limit:      int32(pagination.Limit),
+		HasNext:    pagination.HasNext,
+		NextCursor: pagination.NextCursor,
+		PrevCursor: pagination.PrevCursor,
+	}
+}
+
+func toPaging(paging *bookv1.Paging) models.Paging {
+	return models.Paging{
+		Page:   int(paging.GetPage()),
+		Limit:  int(paging.GetLimit()),
+		Cursor: paging.GetCursor(),
+	}
+}
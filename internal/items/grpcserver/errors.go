@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"errors"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var codeByKind = map[apperrors.Kind]codes.Code{
+	apperrors.Internal:           codes.Internal,
+	apperrors.Invalid:            codes.InvalidArgument,
+	apperrors.NotFound:           codes.NotFound,
+	apperrors.Conflict:           codes.Aborted,
+	apperrors.Validation:         codes.InvalidArgument,
+	apperrors.Unavailable:        codes.Unavailable,
+	apperrors.PreconditionFailed: codes.FailedPrecondition,
+}
+
+// statusError turns err into a gRPC status with the code its kind maps to.
+// Field level details travel as a BadRequest; the text of internal errors is
+// never sent to the client.
+func statusError(err error) error {
+	code := codeByKind[apperrors.KindOf(err)]
+
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || code == codes.Internal {
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	st := status.New(code, appErr.Message)
+	if len(appErr.Details) == 0 {
+		return st.Err()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, detail := range appErr.Details {
+		field := detail.Field
+		if field == "" {
+			field = detail.Param
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: detail.Reason,
+		})
+	}
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		return detailed.Err()
+	}
+	return st.Err()
+}
+
+// requireVersion is the gRPC side of a missing If-Match: writes must say
+// which version of the book they were made against.
+func requireVersion(expectedVersion int64) error {
+	if expectedVersion <= 0 {
+		return status.Error(codes.FailedPrecondition, "expected_version is required")
+	}
+	return nil
+}
+
+func invalid(format string, args ...interface{}) error {
+	return status.Errorf(codes.InvalidArgument, format, args...)
+}
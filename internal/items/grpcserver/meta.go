@@ -0,0 +1,83 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/boock/internal/pkg/reqmeta"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	actorKey     = "x-actor"
+	requestIdKey = "x-request-id"
+
+	// grpcMethod stands in for the HTTP method in the request metadata.
+	grpcMethod = "GRPC"
+)
+
+// withRequestMeta does for gRPC calls what the RequestMeta middleware does for
+// HTTP requests: the caller's metadata ends up on the context, and a missing
+// request ID is generated and sent back in the response header.
+func withRequestMeta(ctx context.Context, fullMethod string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestId := strings.TrimSpace(first(md, requestIdKey))
+	if requestId == "" || len(requestId) > 64 {
+		requestId = uuid.New().String()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIdKey, requestId))
+
+	meta := reqmeta.Meta{
+		Actor:     reqmeta.Actor(first(md, actorKey)),
+		Method:    grpcMethod,
+		Route:     fullMethod,
+		RequestId: requestId,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		meta.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(meta.IP); err == nil {
+			meta.IP = host
+		}
+	}
+	return reqmeta.With(ctx, meta)
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func unaryRequestMeta(logger *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		logger.Println("-- RECEIVED A gRPC REQUEST IN", info.FullMethod, "--")
+		return handler(withRequestMeta(ctx, info.FullMethod), req)
+	}
+}
+
+func streamRequestMeta(logger *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		logger.Println("-- RECEIVED A gRPC REQUEST IN", info.FullMethod, "--")
+		return handler(srv, &metaStream{
+			ServerStream: stream,
+			ctx:          withRequestMeta(stream.Context(), info.FullMethod),
+		})
+	}
+}
+
+// metaStream swaps the context of a stream for one carrying request metadata.
+type metaStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *metaStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,66 @@
+package grpcserver
+
+import (
+	"log"
+	"net"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	bookv1 "github.com/ruziba3vich/boock/internal/pkg/pb/book/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type (
+	// Server serves the book API over gRPC, on top of the same service the
+	// HTTP handlers use.
+	Server struct {
+		bookv1.UnimplementedBookServiceServer
+		service repository.IBookRepo
+		// trashRetention is how long deleted books stay in the trash, which
+		// PurgeDeletedBooks may not cut short.
+		trashRetention time.Duration
+		now            func() time.Time
+		logger         *log.Logger
+	}
+)
+
+func New(service repository.IBookRepo, trashRetention time.Duration, logger *log.Logger) *Server {
+	return &Server{
+		service:        service,
+		trashRetention: trashRetention,
+		now:            time.Now,
+		logger:         logger,
+	}
+}
+
+// Run serves server on host until the listener fails.
+func Run(server *Server, logger *log.Logger, host string) error {
+	listener, err := net.Listen("tcp", host)
+	if err != nil {
+		return err
+	}
+	logger.Println("gRPC server listening on", listener.Addr())
+	return newGRPCServer(server, logger).Serve(listener)
+}
+
+// newGRPCServer registers server together with the standard health and
+// reflection services.
+func newGRPCServer(server *Server, logger *log.Logger) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryRequestMeta(logger)),
+		grpc.ChainStreamInterceptor(streamRequestMeta(logger)),
+	)
+
+	bookv1.RegisterBookServiceServer(s, server)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(bookv1.BookService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	reflection.Register(s)
+	return s
+}
@@ -0,0 +1,368 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+	bookv1 "github.com/ruziba3vich/boock/internal/pkg/pb/book/v1"
+	"github.com/ruziba3vich/boock/internal/pkg/reqmeta"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type (
+	// fakeBooks answers from books, or fails every call with err, and
+	// records what it was asked.
+	fakeBooks struct {
+		repository.IBookRepo
+		books []*models.Book
+		err   error
+
+		meta   reqmeta.Meta
+		calls  int
+		list   *models.GetAllBooksRequest
+		search *models.SearchBooksRequest
+		purge  *models.PurgeDeletedBooksRequest
+	}
+)
+
+func (f *fakeBooks) CreateBook(ctx context.Context, req *models.CreateBookRequest) (*models.Book, error) {
+	f.calls++
+	f.meta = reqmeta.From(ctx)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.Book{BookId: "b1", Title: req.Title, Author: req.Author, PublishedYear: req.PublishedYear, Version: 1, UpdatedAt: testNow}, nil
+}
+
+func (f *fakeBooks) UpdateBook(context.Context, *models.UpdateBookRequest) (*models.Book, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func (f *fakeBooks) PatchBook(context.Context, *models.PatchBookRequest) (*models.Book, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func (f *fakeBooks) DeleteBookById(context.Context, *models.DeleteBookByIdRequest) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeBooks) RevertBook(context.Context, *models.RevertBookRequest) (*models.Book, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func (f *fakeBooks) GetAllBooks(ctx context.Context, req *models.GetAllBooksRequest) (*models.GetSeveralResponse, error) {
+	f.calls++
+	f.meta = reqmeta.From(ctx)
+	f.list = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.GetSeveralResponse{
+		Books:      f.books,
+		Pagination: &models.Pagination{Total: int64(len(f.books)), Limit: req.Limit, NextCursor: "next"},
+	}, nil
+}
+
+func (f *fakeBooks) SearchBooks(_ context.Context, req *models.SearchBooksRequest) (*models.SearchBooksResponse, error) {
+	f.calls++
+	f.search = req
+	response := &models.SearchBooksResponse{}
+	for i, book := range f.books {
+		response.Results = append(response.Results, &models.SearchResult{Book: book, Rank: float64(len(f.books) - i)})
+	}
+	return response, f.err
+}
+
+func (f *fakeBooks) PurgeDeletedBooks(_ context.Context, req *models.PurgeDeletedBooksRequest) (*models.PurgeDeletedBooksResponse, error) {
+	f.calls++
+	f.purge = req
+	return &models.PurgeDeletedBooksResponse{Purged: 3}, f.err
+}
+
+// testClient serves books over an in-memory connection and returns a client
+// for it.
+func testClient(t *testing.T, books *fakeBooks) bookv1.BookServiceClient {
+	t.Helper()
+	logger := log.New(io.Discard, "", 0)
+	server := New(books, 30*24*time.Hour, logger)
+	server.now = func() time.Time { return testNow }
+
+	listener := bufconn.Listen(1 << 20)
+	s := newGRPCServer(server, logger)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return bookv1.NewBookServiceClient(conn)
+}
+
+func TestCreateBookRoundTrip(t *testing.T) {
+	books := &fakeBooks{}
+	client := testClient(t, books)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), actorKey, "alice", requestIdKey, "req-1")
+
+	var header metadata.MD
+	book, err := client.CreateBook(ctx, &bookv1.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", PublishedYear: 1965}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if book.GetBookId() != "b1" || book.GetTitle() != "Dune" || book.GetPublishedYear() != 1965 || !book.GetUpdatedAt().AsTime().Equal(testNow) {
+		t.Errorf("got %v", book)
+	}
+	if books.meta.Actor != "alice" || books.meta.RequestId != "req-1" || books.meta.Method != grpcMethod || books.meta.Route != bookv1.BookService_CreateBook_FullMethodName {
+		t.Errorf("the service saw %+v", books.meta)
+	}
+	if got := header.Get(requestIdKey); len(got) != 1 || got[0] != "req-1" {
+		t.Errorf("got request id header %v", got)
+	}
+}
+
+func TestMissingRequestIdIsGenerated(t *testing.T) {
+	client := testClient(t, &fakeBooks{})
+	var header metadata.MD
+	if _, err := client.CreateBook(context.Background(), &bookv1.CreateBookRequest{Title: "Dune"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if got := header.Get(requestIdKey); len(got) != 1 || len(got[0]) != 36 {
+		t.Errorf("got request id header %v, want a UUID", got)
+	}
+}
+
+func TestRequireVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(bookv1.BookServiceClient) error
+	}{
+		{"update", func(c bookv1.BookServiceClient) error {
+			_, err := c.UpdateBook(context.Background(), &bookv1.UpdateBookRequest{BookId: "b1", Title: "Dune"})
+			return err
+		}},
+		{"patch", func(c bookv1.BookServiceClient) error {
+			_, err := c.PatchBook(context.Background(), &bookv1.PatchBookRequest{BookId: "b1", PatchType: models.MergePatchType, Patch: []byte(`{}`)})
+			return err
+		}},
+		{"delete", func(c bookv1.BookServiceClient) error {
+			_, err := c.DeleteBook(context.Background(), &bookv1.DeleteBookRequest{BookId: "b1", ExpectedVersion: -1})
+			return err
+		}},
+		{"revert", func(c bookv1.BookServiceClient) error {
+			_, err := c.RevertBook(context.Background(), &bookv1.RevertBookRequest{BookId: "b1", Revision: 1})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books := &fakeBooks{}
+			err := tt.call(testClient(t, books))
+			if status.Code(err) != codes.FailedPrecondition {
+				t.Fatalf("got %v, want FailedPrecondition", err)
+			}
+			if books.calls != 0 {
+				t.Error("the service was called without a version")
+			}
+		})
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	details := []apperrors.Detail{
+		{Field: "title", Reason: "title is required"},
+		{Param: "filter", Term: "x", Reason: "expected field:operator:value"},
+	}
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{"not found", apperrors.New(apperrors.NotFound, "book not found"), codes.NotFound, "book not found"},
+		{"invalid", apperrors.New(apperrors.Invalid, "bad cursor"), codes.InvalidArgument, "bad cursor"},
+		{"validation", apperrors.WithDetails(apperrors.Validation, details, "invalid book"), codes.InvalidArgument, "invalid book"},
+		{"conflict", apperrors.New(apperrors.Conflict, "already exists"), codes.Aborted, "already exists"},
+		{"precondition", apperrors.New(apperrors.PreconditionFailed, "version mismatch"), codes.FailedPrecondition, "version mismatch"},
+		{"unavailable", apperrors.New(apperrors.Unavailable, "database is down"), codes.Unavailable, "database is down"},
+		{"wrapped", apperrors.Wrap(apperrors.NotFound, errors.New("sql: no rows"), "book not found"), codes.NotFound, "book not found"},
+		// Internal errors, and errors of no kind, keep their text to themselves.
+		{"internal", apperrors.Wrap(apperrors.Internal, errors.New("pq: password authentication failed"), "query failed"), codes.Internal, "internal error"},
+		{"plain", errors.New("dial tcp 10.0.0.5:5432: connection refused"), codes.Internal, "internal error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(statusError(tt.err))
+			if st.Code() != tt.wantCode || st.Message() != tt.wantMessage {
+				t.Errorf("got %s %q, want %s %q", st.Code(), st.Message(), tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestStatusErrorCarriesDetailsToTheClient(t *testing.T) {
+	books := &fakeBooks{err: apperrors.WithDetails(apperrors.Validation, []apperrors.Detail{
+		{Field: "title", Reason: "title is required"},
+		{Param: "filter", Reason: "unknown field"},
+	}, "invalid book")}
+	_, err := testClient(t, books).CreateBook(context.Background(), &bookv1.CreateBookRequest{})
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("got %v with details %v", err, st.Details())
+	}
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(badRequest.FieldViolations) != 2 {
+		t.Fatalf("got details %v", st.Details())
+	}
+	// A detail without a field is reported under its query parameter.
+	if v := badRequest.FieldViolations[1]; v.Field != "filter" || v.Description != "unknown field" {
+		t.Errorf("got violation %v", v)
+	}
+}
+
+func TestListBooksStreams(t *testing.T) {
+	books := &fakeBooks{books: []*models.Book{{BookId: "b1", Title: "Anna Karenina"}, {BookId: "b2", Title: "War and Peace"}}}
+	client := testClient(t, books)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), actorKey, "bob")
+
+	stream, err := client.ListBooks(ctx, &bookv1.ListBooksRequest{Filter: "author:eq:Tolstoy", Sort: "-title", Paging: &bookv1.Paging{Limit: 2, Cursor: "c"}})
+	if err != nil {
+		t.Fatalf("ListBooks: %v", err)
+	}
+	var (
+		ids        []string
+		pagination *bookv1.Pagination
+	)
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if pagination != nil {
+			t.Fatal("a message came after the pagination")
+		}
+		if book := message.GetBook(); book != nil {
+			ids = append(ids, book.GetBookId())
+		} else {
+			pagination = message.GetPagination()
+		}
+	}
+
+	if len(ids) != 2 || ids[0] != "b1" || ids[1] != "b2" {
+		t.Errorf("got books %v", ids)
+	}
+	if pagination.GetTotal() != 2 || pagination.GetNextCursor() != "next" {
+		t.Errorf("got pagination %v", pagination)
+	}
+	if books.list.Filter != "author:eq:Tolstoy" || books.list.Sort != "-title" || books.list.Limit != 2 || books.list.Cursor != "c" {
+		t.Errorf("the service was asked for %+v", books.list)
+	}
+	if books.meta.Actor != "bob" {
+		t.Errorf("the stream carried %+v", books.meta)
+	}
+}
+
+func TestListBooksStreamsErrors(t *testing.T) {
+	books := &fakeBooks{err: apperrors.New(apperrors.Invalid, "invalid filter or sort parameter")}
+	stream, err := testClient(t, books).ListBooks(context.Background(), &bookv1.ListBooksRequest{Filter: "isbn:eq:1"})
+	if err != nil {
+		t.Fatalf("ListBooks: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument", err)
+	}
+}
+
+func TestSearchBooksStreams(t *testing.T) {
+	books := &fakeBooks{books: []*models.Book{{BookId: "b1"}, {BookId: "b2"}, {BookId: "b3"}}}
+	client := testClient(t, books)
+
+	stream, err := client.SearchBooks(context.Background(), &bookv1.SearchBooksRequest{Search: "war"})
+	if err != nil {
+		t.Fatalf("SearchBooks: %v", err)
+	}
+	var ranks []float64
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		ranks = append(ranks, result.GetRank())
+	}
+	if len(ranks) != 3 || ranks[0] != 3 || ranks[2] != 1 {
+		t.Errorf("got ranks %v", ranks)
+	}
+	if books.search.Limit != defaultSearchLimit {
+		t.Errorf("searched with limit %d, want %d", books.search.Limit, defaultSearchLimit)
+	}
+
+	stream, err = client.SearchBooks(context.Background(), &bookv1.SearchBooksRequest{})
+	if err != nil {
+		t.Fatalf("SearchBooks: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("got %v for an empty search, want InvalidArgument", err)
+	}
+}
+
+func TestPurgeDeletedBooksKeepsTheRetentionWindow(t *testing.T) {
+	latest := testNow.Add(-30 * 24 * time.Hour)
+	tests := []struct {
+		name          string
+		deletedBefore *timestamppb.Timestamp
+		wantCode      codes.Code
+	}{
+		{"missing", nil, codes.InvalidArgument},
+		{"now", timestamppb.New(testNow), codes.InvalidArgument},
+		{"inside the window", timestamppb.New(latest.Add(time.Second)), codes.InvalidArgument},
+		{"end of the window", timestamppb.New(latest), codes.OK},
+		{"before the window", timestamppb.New(latest.Add(-time.Hour)), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books := &fakeBooks{}
+			response, err := testClient(t, books).PurgeDeletedBooks(context.Background(), &bookv1.PurgeDeletedBooksRequest{DeletedBefore: tt.deletedBefore})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("got %v, want %s", err, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				if books.calls != 0 {
+					t.Error("the service was called")
+				}
+				return
+			}
+			if response.GetPurged() != 3 || !books.purge.DeletedBefore.Equal(tt.deletedBefore.AsTime()) {
+				t.Errorf("got %v, purged before %s", response, books.purge.DeletedBefore)
+			}
+		})
+	}
+}
@@ -1,25 +1,101 @@
 package app
 
 import (
-	"log"
+	"expvar"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	v1 "github.com/ruziba3vich/boock/internal/items/http/dto/v1"
+	v2 "github.com/ruziba3vich/boock/internal/items/http/dto/v2"
 	"github.com/ruziba3vich/boock/internal/items/http/handler"
+	"github.com/ruziba3vich/boock/internal/items/http/openapi"
 )
 
-func Run(router *gin.Engine, handler *handler.Handler, logger *log.Logger, host string) error {
-	r := router.Group("/books")
+func Run(router *gin.Engine, handler *handler.Handler, api config.APIConfig, host string) error {
+	if _, err := register(router, handler, api); err != nil {
+		return err
+	}
+	return router.Run(host)
+}
 
-	r.POST("", handler.CreateBookHandler)
-	r.PUT("", handler.UpdateBookHandler)
-	r.GET("/:id", handler.GetBookByIdHandler)
-	r.GET("/all", handler.GetAllBooksHandler)
-	r.GET("/author", handler.GetBooksByAuthorHandler)
-	r.GET("/name", handler.GetBooksByNameHandler)
-	r.GET("/search", handler.SearchBooksHandler)
-	r.DELETE("/:id", handler.DeleteBookByIdHandler)
+// register adds every route to router and returns the OpenAPI document that
+// describes them. The tests check the two against each other.
+func register(router *gin.Engine, handler *handler.Handler, api config.APIConfig) (*openapi.Document, error) {
+	versions := apiVersions(api)
+	for _, version := range versions {
+		routes(router.Group(version.Prefix, handler.Versioned(version)), handler)
+	}
 
-	return router.Run(host)
+	// Mutations made through GraphQL are audited one by one by the service,
+	// so the group has no AuditLog. GraphQL over HTTP answers in JSON only,
+	// so it has no Negotiate either.
+	g := router.Group("/graphql", handler.RequestMeta)
+
+	g.GET("", handler.GraphQLQueryHandler)
+	g.POST("", handler.GraphQLHandler)
+
+	router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+
+	spec := openapi.New(versions)
+	serveSpec, err := spec.Serve()
+	if err != nil {
+		return nil, err
+	}
+	router.GET("/openapi.json", serveSpec)
+	router.GET(openapi.DocsPath+"/*any", openapi.SwaggerUI("/openapi.json"))
+
+	return spec, nil
+}
+
+// apiVersions are the route prefixes and what they serve. The unversioned
+// routes are kept for clients from before versioning and speak v1.
+func apiVersions(api config.APIConfig) []handler.APIVersion {
+	current := "/" + v2.Version{}.Name()
+	return []handler.APIVersion{
+		{Prefix: "", Wire: v1.Version{}, Deprecation: api.Legacy, Successor: current},
+		{Prefix: "/v1", Wire: v1.Version{}, Deprecation: api.V1, Successor: current},
+		{Prefix: current, Wire: v2.Version{}},
+	}
+}
+
+// routes registers the versioned routes on r.
+func routes(r *gin.RouterGroup, handler *handler.Handler) {
+	books := r.Group("/books", handler.RequestMeta, handler.AuditLog)
+
+	// The event stream is always text/event-stream.
+	books.GET("/events", handler.BookEventsHandler)
+
+	b := books.Group("", handler.Negotiate)
+
+	b.POST("", handler.CreateBookHandler)
+	b.PUT("/:id", handler.UpdateBookHandler)
+	b.PATCH("/:id", handler.PatchBookHandler)
+	b.GET("/:id", handler.GetBookByIdHandler)
+	b.GET("/all", handler.GetAllBooksHandler)
+	b.GET("/author", handler.GetBooksByAuthorHandler)
+	b.GET("/name", handler.GetBooksByNameHandler)
+	b.GET("/search", handler.SearchBooksHandler)
+	b.GET("/trash", handler.ListTrashHandler)
+	b.POST("/:id/restore", handler.RestoreBookHandler)
+	b.GET("/:id/history", handler.GetBookHistoryHandler)
+	b.POST("/:id/revert/:revision", handler.RevertBookHandler)
+	b.DELETE("/:id", handler.DeleteBookByIdHandler)
+
+	w := r.Group("/webhooks", handler.RequestMeta, handler.AuditLog, handler.Negotiate)
+
+	w.POST("", handler.CreateWebhookHandler)
+	w.GET("", handler.ListWebhooksHandler)
+	w.GET("/:id", handler.GetWebhookHandler)
+	w.PUT("/:id", handler.UpdateWebhookHandler)
+	w.DELETE("/:id", handler.DeleteWebhookHandler)
+	w.GET("/:id/deliveries", handler.ListWebhookDeliveriesHandler)
+	w.POST("/:id/deliveries/replay", handler.ReplayWebhookDeliveriesHandler)
+	w.POST("/:id/deliveries/:delivery/replay", handler.ReplayWebhookDeliveriesHandler)
+
+	a := r.Group("/audit", handler.RequestMeta, handler.Negotiate)
+
+	a.GET("", handler.QueryAuditLogHandler)
+	a.GET("/verify", handler.VerifyAuditLogHandler)
 }
 
 /*
@@ -29,6 +105,6 @@ func Run(router *gin.Engine, handler *handler.Handler, logger *log.Logger, host
 	GetAllBooks(context.Context, *models.GetAllBooksRequest) (*models.GetSeveralResponse, error)
 	GetBooksByAuthor(context.Context, *models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error)
 	GetBooksByName(context.Context, *models.GetBooksByNameRequest) (*models.GetSeveralResponse, error)
-	SearchBooks(context.Context, *models.SearchBooksRequest) (*models.GetSeveralResponse, error)
+	SearchBooks(context.Context, *models.SearchBooksRequest) (*models.SearchBooksResponse, error)
 	DeleteBookById(context.Context,*models.DeleteBookByIdRequest) error
 */
@@ -0,0 +1,365 @@
+package app
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/gql"
+	"github.com/ruziba3vich/boock/internal/items/http/handler"
+	"github.com/ruziba3vich/boock/internal/items/http/openapi"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+var updatedAt = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type (
+	fakeBooks struct {
+		repository.IBookRepo
+	}
+	fakeAudit struct {
+		repository.IAuditRepo
+	}
+	fakeWebhooks struct {
+		repository.IWebhookRepo
+	}
+)
+
+func testBook() *models.Book {
+	return &models.Book{BookId: "b1", Title: "Dune", Author: "Frank Herbert", PublishedYear: 1965, Version: 1, UpdatedAt: updatedAt}
+}
+
+func testBooks() *models.GetSeveralResponse {
+	return &models.GetSeveralResponse{Books: []*models.Book{testBook()}, Pagination: &models.Pagination{Page: 1, Limit: 10, Total: 1}}
+}
+
+func (fakeBooks) CreateBook(context.Context, *models.CreateBookRequest) (*models.Book, error) {
+	return testBook(), nil
+}
+
+func (fakeBooks) GetBookById(_ context.Context, req *models.GetBookByIdRequest) (*models.Book, error) {
+	if req.BookId != "b1" {
+		return nil, apperrors.New(apperrors.NotFound, "book %s not found", req.BookId)
+	}
+	return testBook(), nil
+}
+
+func (fakeBooks) GetAllBooks(context.Context, *models.GetAllBooksRequest) (*models.GetSeveralResponse, error) {
+	return testBooks(), nil
+}
+
+func (fakeBooks) ListTrash(context.Context, *models.ListTrashRequest) (*models.GetSeveralResponse, error) {
+	return testBooks(), nil
+}
+
+func (fakeBooks) SearchBooks(context.Context, *models.SearchBooksRequest) (*models.SearchBooksResponse, error) {
+	return &models.SearchBooksResponse{Results: []*models.SearchResult{{Book: testBook(), Rank: 0.5, Highlights: models.SearchHighlights{Title: "<b>Dune</b>"}}}}, nil
+}
+
+func (fakeBooks) GetBookHistory(context.Context, *models.GetBookHistoryRequest) (*models.GetBookHistoryResponse, error) {
+	return &models.GetBookHistoryResponse{Revisions: []*models.BookRevision{
+		{Revision: 1, Operation: models.RevisionCreate, RecordedAt: updatedAt, Book: testBook()},
+	}}, nil
+}
+
+func (fakeBooks) GetCatalogVersion(context.Context) (*models.CatalogVersion, error) {
+	return &models.CatalogVersion{ModifiedAt: updatedAt}, nil
+}
+
+func (fakeAudit) AppendAuditEntry(context.Context, *models.AuditEntry) error {
+	return nil
+}
+
+func (fakeAudit) QueryAuditLog(context.Context, *models.QueryAuditLogRequest) (*models.QueryAuditLogResponse, error) {
+	return &models.QueryAuditLogResponse{Entries: []*models.AuditEntry{{Id: 1, Actor: "alice", Method: http.MethodPost, Route: "/books", Status: http.StatusCreated, OccurredAt: updatedAt}}}, nil
+}
+
+func (fakeAudit) VerifyAuditLog(context.Context) (*models.VerifyAuditLogResponse, error) {
+	return &models.VerifyAuditLogResponse{Checked: 1, Valid: true}, nil
+}
+
+func (fakeWebhooks) GetWebhook(context.Context, *models.GetWebhookRequest) (*models.Webhook, error) {
+	return &models.Webhook{Id: "w1", URL: "https://example.com/hook", Events: []string{}, Active: true, CreatedAt: updatedAt}, nil
+}
+
+func (f fakeWebhooks) ListWebhooks(ctx context.Context) (*models.ListWebhooksResponse, error) {
+	webhook, _ := f.GetWebhook(ctx, nil)
+	return &models.ListWebhooksResponse{Webhooks: []*models.Webhook{webhook}}, nil
+}
+
+// testRouter registers the routes the way Run does.
+func testRouter(t *testing.T, h *handler.Handler) (*gin.Engine, *openapi.Document) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	spec, err := register(router, h, config.APIConfig{})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return router, spec
+}
+
+func TestDocumentMatchesRoutes(t *testing.T) {
+	router, spec := testRouter(t, handler.New(nil, nil, nil, nil, nil, log.New(io.Discard, "", 0)))
+	if err := spec.Check(router.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHandlersReadDocumentedQueryParameters compares the query parameters
+// each route documents with the ones its handler reads, found in the source
+// of the handler package.
+func TestHandlersReadDocumentedQueryParameters(t *testing.T) {
+	router, spec := testRouter(t, handler.New(nil, nil, nil, nil, nil, log.New(io.Discard, "", 0)))
+	reads := queryReads(t, filepath.Join("..", "handler"))
+
+	const prefix = "/http/handler.(*Handler)."
+	for _, route := range router.Routes() {
+		_, method, ok := strings.Cut(route.Handler, prefix)
+		if !ok {
+			continue
+		}
+		method = strings.TrimSuffix(method, "-fm")
+
+		read := reads.of(method, map[string]bool{})
+		documented := map[string]bool{}
+		for _, name := range spec.Params(spec.Operation(route.Method, route.Path), "query") {
+			documented[name] = true
+		}
+		for name := range read {
+			if !documented[name] {
+				t.Errorf("%s %s reads query parameter %q, which is not documented", route.Method, route.Path, name)
+			}
+		}
+		for name := range documented {
+			if !read[name] {
+				t.Errorf("%s %s documents query parameter %q, which %s does not read", route.Method, route.Path, name, method)
+			}
+		}
+	}
+}
+
+// sourceReads are the query parameters each function of a package reads
+// itself, and the functions and methods of the package it calls.
+type sourceReads map[string]*funcReads
+
+type funcReads struct {
+	params map[string]bool
+	calls  map[string]bool
+}
+
+// of is every query parameter name reads, directly or through the calls it
+// makes.
+func (r sourceReads) of(name string, seen map[string]bool) map[string]bool {
+	params := map[string]bool{}
+	if seen[name] || r[name] == nil {
+		return params
+	}
+	seen[name] = true
+	for param := range r[name].params {
+		params[param] = true
+	}
+	for call := range r[name].calls {
+		for param := range r.of(call, seen) {
+			params[param] = true
+		}
+	}
+	return params
+}
+
+func queryReads(t *testing.T, dir string) sourceReads {
+	t.Helper()
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reads := sourceReads{}
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			reads[fn.Name.Name] = funcQueryReads(t, fset, fn.Body)
+		}
+	}
+	return reads
+}
+
+var queryMethods = map[string]bool{"Query": true, "DefaultQuery": true, "GetQuery": true, "QueryArray": true, "GetQueryArray": true}
+
+func funcQueryReads(t *testing.T, fset *token.FileSet, body *ast.BlockStmt) *funcReads {
+	reads := &funcReads{params: map[string]bool{}, calls: map[string]bool{}}
+	// ranged holds the names a loop variable takes when ranging over a
+	// literal, as in: for param := range map[string]T{"from": ...}.
+	ranged := map[string][]string{}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.RangeStmt:
+			literal, ok := node.X.(*ast.CompositeLit)
+			if !ok {
+				break
+			}
+			for _, element := range literal.Elts {
+				if pair, ok := element.(*ast.KeyValueExpr); ok && node.Key != nil {
+					ranged[node.Key.(*ast.Ident).Name] = append(ranged[node.Key.(*ast.Ident).Name], stringLiteral(pair.Key))
+				} else if !ok && node.Value != nil {
+					ranged[node.Value.(*ast.Ident).Name] = append(ranged[node.Value.(*ast.Ident).Name], stringLiteral(element))
+				}
+			}
+		case *ast.CallExpr:
+			switch fun := node.Fun.(type) {
+			case *ast.Ident:
+				reads.calls[fun.Name] = true
+			case *ast.SelectorExpr:
+				if !queryMethods[fun.Sel.Name] {
+					reads.calls[fun.Sel.Name] = true
+					break
+				}
+				if len(node.Args) == 0 {
+					// url.Values, copied whole, as for pagination links.
+					break
+				}
+				if name := stringLiteral(node.Args[0]); name != "" {
+					reads.params[name] = true
+				} else if ident, ok := node.Args[0].(*ast.Ident); ok && ranged[ident.Name] != nil {
+					for _, name := range ranged[ident.Name] {
+						reads.params[name] = true
+					}
+				} else {
+					t.Fatalf("%s: cannot tell which query parameter is read", fset.Position(node.Pos()))
+				}
+			}
+		}
+		return true
+	})
+	return reads
+}
+
+func stringLiteral(expr ast.Expr) string {
+	literal, ok := expr.(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return ""
+	}
+	value, _ := strconv.Unquote(literal.Value)
+	return value
+}
+
+func TestResponsesFollowSchemas(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	graphql, err := gql.New(fakeBooks{}, nil, config.GraphQLConfig{MaxComplexity: 100, MaxDepth: 5}, 100, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, spec := testRouter(t, handler.New(fakeBooks{}, fakeAudit{}, fakeWebhooks{}, nil, graphql, logger))
+
+	type request struct {
+		method, path, route, body string
+		header                    http.Header
+		status                    int
+	}
+	var requests []request
+	for _, prefix := range []string{"", "/v1", "/v2"} {
+		requests = append(requests,
+			request{method: http.MethodPost, path: prefix + "/books", route: prefix + "/books", body: `{"title":"Dune","author":"Frank Herbert","published_year":1965}`, status: http.StatusCreated},
+			request{method: http.MethodGet, path: prefix + "/books/b1", route: prefix + "/books/:id", status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/books/b1", route: prefix + "/books/:id", header: http.Header{"Accept": {"application/xml"}}, status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/books/b2", route: prefix + "/books/:id", status: http.StatusNotFound},
+			request{method: http.MethodGet, path: prefix + "/books/b1", route: prefix + "/books/:id", header: http.Header{"Accept": {"image/png"}}, status: http.StatusNotAcceptable},
+			request{method: http.MethodGet, path: prefix + "/books/all?pretty=1", route: prefix + "/books/all", status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/books/trash", route: prefix + "/books/trash", status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/books/search?search=dune", route: prefix + "/books/search", status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/books/b1/history", route: prefix + "/books/:id/history", status: http.StatusOK},
+			request{method: http.MethodDelete, path: prefix + "/books/b1", route: prefix + "/books/:id", status: http.StatusPreconditionRequired},
+			request{method: http.MethodGet, path: prefix + "/webhooks", route: prefix + "/webhooks", status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/webhooks/w1", route: prefix + "/webhooks/:id", status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/audit", route: prefix + "/audit", status: http.StatusOK},
+			request{method: http.MethodGet, path: prefix + "/audit/verify", route: prefix + "/audit/verify", status: http.StatusOK},
+		)
+	}
+	requests = append(requests,
+		request{method: http.MethodPost, path: "/graphql", route: "/graphql", body: `{"query":"{ __typename }"}`, status: http.StatusOK},
+		request{method: http.MethodGet, path: "/openapi.json", route: "/openapi.json", status: http.StatusOK},
+	)
+
+	for _, req := range requests {
+		t.Run(req.method+" "+req.path+" "+req.header.Get("Accept"), func(t *testing.T) {
+			r := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+			if req.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			for name, values := range req.header {
+				r.Header[name] = values
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != req.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, req.status, w.Body)
+			}
+			if err := spec.CheckResponse(req.method, req.route, w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestCheckResponseCatchesDrift makes sure the schema check above would
+// fail on the kind of drift it is there for.
+func TestCheckResponseCatchesDrift(t *testing.T) {
+	_, spec := testRouter(t, handler.New(nil, nil, nil, nil, nil, log.New(io.Discard, "", 0)))
+	tests := []struct {
+		name   string
+		route  string
+		status int
+		body   string
+		want   []string
+	}{
+		{"v1 book as v2", "/v2/books/:id", http.StatusOK, `{"book_id":"b1","title":"Dune","author":"Frank Herbert","published_year":1965,"version":1,"updated_at":"2024-06-01T12:00:00Z"}`, []string{"$.book_id is not documented", "$.id is missing"}},
+		{"wrong type", "/books/:id", http.StatusOK, `{"book_id":"b1","title":"Dune","author":"Frank Herbert","published_year":"1965","version":1,"updated_at":"2024-06-01T12:00:00Z"}`, []string{"$.published_year is not an integer"}},
+		{"undocumented status", "/books/:id", http.StatusTeapot, `{}`, []string{"418, which is not documented"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.CheckResponse(http.MethodGet, tt.route, tt.status, "application/json", []byte(tt.body))
+			if err == nil {
+				t.Fatal("no problem reported")
+			}
+			var missing []string
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					missing = append(missing, want)
+				}
+			}
+			sort.Strings(missing)
+			if len(missing) > 0 {
+				t.Errorf("%v does not report %v", err, missing)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Package dto describes the wire format of an API version. Each version
+// lives in its own package and maps its types to and from the shared
+// models, so a model can change without changing what clients see.
+package dto
+
+import "github.com/ruziba3vich/boock/internal/models"
+
+type (
+	Version interface {
+		// Name is the path segment of the version, such as "v1".
+		Name() string
+		// Response converts a model to the type this version sends. Values
+		// the version has no type of its own for are returned as they are.
+		Response(value interface{}) interface{}
+		CreateBookRequest() CreateBookRequest
+		UpdateBookRequest() UpdateBookRequest
+		// BookDocument is the book as this version shows it, for patches
+		// to apply to.
+		BookDocument() models.BookDocument
+	}
+	// CreateBookRequest is a request body, to be bound and then turned
+	// into the model.
+	CreateBookRequest interface {
+		Model() *models.CreateBookRequest
+	}
+	UpdateBookRequest interface {
+		Model() *models.UpdateBookRequest
+	}
+)
@@ -0,0 +1,195 @@
+// Package v1 is the first version of the HTTP API, which is also served on
+// the unversioned routes.
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/http/dto"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	Version struct{}
+
+	Book struct {
+		BookId        string     `json:"book_id"`
+		Title         string     `json:"title"`
+		Author        string     `json:"author"`
+		PublishedYear int        `json:"published_year"`
+		Version       int64      `json:"version"`
+		UpdatedAt     time.Time  `json:"updated_at"`
+		DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	}
+	// The lists and events of books are shared with the later versions,
+	// which differ only in the book, B.
+	BookList[B any] struct {
+		Books      []B                `json:"books"`
+		Pagination *models.Pagination `json:"pagination,omitempty"`
+	}
+	SearchResults[B any] struct {
+		Results []*SearchResult[B] `json:"results"`
+	}
+	SearchResult[B any] struct {
+		Book       B                       `json:"book"`
+		Rank       float64                 `json:"rank"`
+		Highlights models.SearchHighlights `json:"highlights"`
+	}
+	BookHistory[B any] struct {
+		Revisions  []*BookRevision[B] `json:"revisions"`
+		Pagination *models.Pagination `json:"pagination,omitempty"`
+	}
+	BookRevision[B any] struct {
+		Revision   int64     `json:"revision"`
+		Operation  string    `json:"operation"`
+		ChangedBy  string    `json:"changed_by,omitempty"`
+		RecordedAt time.Time `json:"recorded_at"`
+		Book       B         `json:"book"`
+	}
+	BookEvent[B any] struct {
+		EventId    string    `json:"event_id"`
+		Type       string    `json:"type"`
+		BookId     string    `json:"book_id"`
+		Version    int64     `json:"version"`
+		OccurredAt time.Time `json:"occurred_at"`
+		Book       B         `json:"book"`
+	}
+
+	// BookDocument patches a book as Book.
+	BookDocument struct{}
+
+	CreateBookRequest struct {
+		Title         string `json:"title"`
+		Author        string `json:"author"`
+		PublishedYear int    `json:"published_year"`
+	}
+	// UpdateBookRequest takes the book ID from the path.
+	UpdateBookRequest struct {
+		Title         string `json:"title"`
+		Author        string `json:"author"`
+		PublishedYear int    `json:"published_year"`
+	}
+)
+
+func (Version) Name() string {
+	return "v1"
+}
+
+func (Version) Response(value interface{}) interface{} {
+	return Response(value, NewBook)
+}
+
+// Response converts value to the types of a version whose book is built by
+// newBook.
+func Response[B any](value interface{}, newBook func(*models.Book) B) interface{} {
+	switch value := value.(type) {
+	case *models.Book:
+		return newBook(value)
+	case *models.GetSeveralResponse:
+		books := make([]B, len(value.Books))
+		for i, book := range value.Books {
+			books[i] = newBook(book)
+		}
+		return &BookList[B]{Books: books, Pagination: value.Pagination}
+	case *models.SearchBooksResponse:
+		results := make([]*SearchResult[B], len(value.Results))
+		for i, result := range value.Results {
+			results[i] = &SearchResult[B]{Book: newBook(result.Book), Rank: result.Rank, Highlights: result.Highlights}
+		}
+		return &SearchResults[B]{Results: results}
+	case *models.GetBookHistoryResponse:
+		revisions := make([]*BookRevision[B], len(value.Revisions))
+		for i, revision := range value.Revisions {
+			revisions[i] = &BookRevision[B]{
+				Revision:   revision.Revision,
+				Operation:  revision.Operation,
+				ChangedBy:  revision.ChangedBy,
+				RecordedAt: revision.RecordedAt,
+				Book:       newBook(revision.Book),
+			}
+		}
+		return &BookHistory[B]{Revisions: revisions, Pagination: value.Pagination}
+	case *models.BookEvent:
+		return &BookEvent[B]{
+			EventId:    value.EventId,
+			Type:       value.Type,
+			BookId:     value.BookId,
+			Version:    value.Version,
+			OccurredAt: value.OccurredAt,
+			Book:       newBook(value.Book),
+		}
+	}
+	return value
+}
+
+func (Version) CreateBookRequest() dto.CreateBookRequest {
+	return &CreateBookRequest{}
+}
+
+func (Version) UpdateBookRequest() dto.UpdateBookRequest {
+	return &UpdateBookRequest{}
+}
+
+func (Version) BookDocument() models.BookDocument {
+	return BookDocument{}
+}
+
+func NewBook(book *models.Book) *Book {
+	if book == nil {
+		return nil
+	}
+	return &Book{
+		BookId:        book.BookId,
+		Title:         book.Title,
+		Author:        book.Author,
+		PublishedYear: book.PublishedYear,
+		Version:       book.Version,
+		UpdatedAt:     book.UpdatedAt,
+		DeletedAt:     book.DeletedAt,
+	}
+}
+
+func (b *Book) Model() *models.Book {
+	return &models.Book{
+		BookId:        b.BookId,
+		Title:         b.Title,
+		Author:        b.Author,
+		PublishedYear: b.PublishedYear,
+		Version:       b.Version,
+		UpdatedAt:     b.UpdatedAt,
+		DeletedAt:     b.DeletedAt,
+	}
+}
+
+func (BookDocument) Marshal(book *models.Book) ([]byte, error) {
+	return json.Marshal(NewBook(book))
+}
+
+func (BookDocument) Unmarshal(data []byte) (*models.Book, error) {
+	var book Book
+	if err := json.Unmarshal(data, &book); err != nil {
+		return nil, err
+	}
+	return book.Model(), nil
+}
+
+func (BookDocument) IdField() string {
+	return "book_id"
+}
+
+func (r *CreateBookRequest) Model() *models.CreateBookRequest {
+	return &models.CreateBookRequest{
+		Title:         r.Title,
+		Author:        r.Author,
+		PublishedYear: r.PublishedYear,
+	}
+}
+
+func (r *UpdateBookRequest) Model() *models.UpdateBookRequest {
+	return &models.UpdateBookRequest{
+		Title:         r.Title,
+		Author:        r.Author,
+		PublishedYear: r.PublishedYear,
+	}
+}
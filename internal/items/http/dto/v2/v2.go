@@ -0,0 +1,85 @@
+// Package v2 is the current version of the HTTP API. A book carries its ID
+// as "id"; everything else is as in v1.
+package v2
+
+import (
+	"encoding/json"
+	"time"
+
+	v1 "github.com/ruziba3vich/boock/internal/items/http/dto/v1"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// Version takes its request bodies from v1.
+	Version struct {
+		v1.Version
+	}
+
+	Book struct {
+		Id            string     `json:"id"`
+		Title         string     `json:"title"`
+		Author        string     `json:"author"`
+		PublishedYear int        `json:"published_year"`
+		Version       int64      `json:"version"`
+		UpdatedAt     time.Time  `json:"updated_at"`
+		DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	}
+	// BookDocument patches a book as Book, so the ID is "id".
+	BookDocument struct{}
+)
+
+func (Version) Name() string {
+	return "v2"
+}
+
+func (Version) Response(value interface{}) interface{} {
+	return v1.Response(value, NewBook)
+}
+
+func (Version) BookDocument() models.BookDocument {
+	return BookDocument{}
+}
+
+func NewBook(book *models.Book) *Book {
+	if book == nil {
+		return nil
+	}
+	return &Book{
+		Id:            book.BookId,
+		Title:         book.Title,
+		Author:        book.Author,
+		PublishedYear: book.PublishedYear,
+		Version:       book.Version,
+		UpdatedAt:     book.UpdatedAt,
+		DeletedAt:     book.DeletedAt,
+	}
+}
+
+func (b *Book) Model() *models.Book {
+	return &models.Book{
+		BookId:        b.Id,
+		Title:         b.Title,
+		Author:        b.Author,
+		PublishedYear: b.PublishedYear,
+		Version:       b.Version,
+		UpdatedAt:     b.UpdatedAt,
+		DeletedAt:     b.DeletedAt,
+	}
+}
+
+func (BookDocument) Marshal(book *models.Book) ([]byte, error) {
+	return json.Marshal(NewBook(book))
+}
+
+func (BookDocument) Unmarshal(data []byte) (*models.Book, error) {
+	var book Book
+	if err := json.Unmarshal(data, &book); err != nil {
+		return nil, err
+	}
+	return book.Model(), nil
+}
+
+func (BookDocument) IdField() string {
+	return "id"
+}
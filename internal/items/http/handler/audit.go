@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/audit"
+	"github.com/ruziba3vich/boock/internal/models"
+	"github.com/ruziba3vich/boock/internal/pkg/reqmeta"
+)
+
+// auditTimeout bounds writing the entry once the response has been sent.
+const auditTimeout = 5 * time.Second
+
+// AuditLog writes one audit entry for every mutating request, whether or not
+// it got as far as the storage. It must run after RequestMeta.
+func (h *Handler) AuditLog(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+
+	ctx, trail := audit.Track(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	meta := reqmeta.From(ctx)
+	entry := &models.AuditEntry{
+		Actor:     meta.Actor,
+		IP:        meta.IP,
+		Method:    meta.Method,
+		Route:     meta.Route,
+		RequestId: meta.RequestId,
+		Status:    c.Writer.Status(),
+		Outcome:   models.AuditSuccess,
+	}
+	if entry.Status >= http.StatusBadRequest {
+		entry.Outcome = models.AuditFailure
+		entry.Error = http.StatusText(entry.Status)
+	}
+	if changes := trail.Changes(); len(changes) > 0 {
+		change := changes[0]
+		entry.Operation = change.Operation
+		entry.BookId = change.BookId
+		entry.Diff = change.Diff
+		if change.Err != nil {
+			entry.Error = change.Err.Error()
+		}
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+	defer cancel()
+	if err := h.auditLog.AppendAuditEntry(writeCtx, entry); err != nil {
+		h.logger.Println("Error writing audit entry:", err)
+	}
+}
+
+func (h *Handler) QueryAuditLogHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN QueryAuditLogHandler --")
+
+	paging, err := parsePaging(c)
+	if err != nil {
+		h.logger.Println("Error parsing paging parameters:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	req := &models.QueryAuditLogRequest{
+		Actor:  c.Query("actor"),
+		Paging: paging,
+	}
+	for param, target := range map[string]*time.Time{"from": &req.From, "to": &req.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.logger.Println("Error parsing", param, "timestamp:", err)
+			h.writeError(c, invalid("Invalid %s timestamp, expected RFC 3339", param))
+			return
+		}
+		*target = t
+	}
+
+	response, err := h.auditLog.QueryAuditLog(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error querying audit log:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	setPaginationLinks(c, response.Pagination)
+	h.respond(c, http.StatusOK, response)
+}
+
+func (h *Handler) VerifyAuditLogHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN VerifyAuditLogHandler --")
+
+	response, err := h.auditLog.VerifyAuditLog(c.Request.Context())
+	if err != nil {
+		h.logger.Println("Error verifying audit log:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, response)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since as
+// RFC 7232 requires, against the current validators of a resource.
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		return etagListMatches(header, etag)
+	}
+	if header := c.GetHeader("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// etagListMatches uses the weak comparison If-None-Match calls for.
+func etagListMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// listNotModified sets the validators of a list response and answers 304
+// when the client's copy is current. The validators only depend on the
+// catalog version, the request and the negotiated format, so no list query
+// is needed to check them.
+func (h *Handler) listNotModified(c *gin.Context) bool {
+	version, err := h.service.GetCatalogVersion(c.Request.Context())
+	if err != nil {
+		h.logger.Println("Error getting catalog version:", err)
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(strconv.FormatInt(version.ModifiedAt.UnixNano(), 10) + "|" + c.Request.URL.RequestURI() + "|" + negotiated(c).Name))
+	etag := `W/"` + hex.EncodeToString(sum[:10]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", version.ModifiedAt.UTC().Format(http.TimeFormat))
+	if notModified(c, etag, version.ModifiedAt) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+)
+
+// Problem is the RFC 7807 problem details body of every error response.
+type Problem struct {
+	Type     string             `json:"type"`
+	Title    string             `json:"title"`
+	Status   int                `json:"status"`
+	Detail   string             `json:"detail,omitempty"`
+	Instance string             `json:"instance,omitempty"`
+	Errors   []apperrors.Detail `json:"errors,omitempty"`
+}
+
+var statusByKind = map[apperrors.Kind]int{
+	apperrors.Internal:           http.StatusInternalServerError,
+	apperrors.Invalid:            http.StatusBadRequest,
+	apperrors.NotFound:           http.StatusNotFound,
+	apperrors.Conflict:           http.StatusConflict,
+	apperrors.Validation:         http.StatusUnprocessableEntity,
+	apperrors.Unavailable:        http.StatusServiceUnavailable,
+	apperrors.PreconditionFailed: http.StatusPreconditionFailed,
+}
+
+// writeError renders err as problem+json with the status its kind maps to.
+// Internal errors are logged but their text is never sent to the client.
+func (h *Handler) writeError(c *gin.Context, err error) {
+	status := statusByKind[apperrors.KindOf(err)]
+
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) && status != http.StatusInternalServerError {
+		h.writeProblem(c, status, appErr.Message, appErr.Details)
+		return
+	}
+	h.writeProblem(c, status, "", nil)
+}
+
+func (h *Handler) writeProblem(c *gin.Context, status int, detail string, details []apperrors.Detail) {
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(status, Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Errors:   details,
+	})
+}
+
+func invalid(format string, args ...interface{}) error {
+	return apperrors.New(apperrors.Invalid, format, args...)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// bookETag is a strong entity tag derived from the row version, so a cached
+// copy of the book yields the same tag as the database row it came from. A
+// strong tag stands for the exact bytes, so the wire version and format of
+// the response, and whether it is indented, are part of it: "3-v2-json".
+func bookETag(c *gin.Context, book *models.Book) string {
+	etag := strconv.FormatInt(book.Version, 10) + "-" + wire(c).Name() + "-" + negotiated(c).Name
+	if pretty(c) {
+		etag += "-pretty"
+	}
+	return `"` + etag + `"`
+}
+
+// ifMatchVersion reads the version a write is conditioned on. A missing
+// header is answered with 428 and an unusable one with 412; "*" matches any
+// version and is returned as zero.
+func (h *Handler) ifMatchVersion(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		h.logger.Println("If-Match header is missing")
+		h.writeProblem(c, http.StatusPreconditionRequired, "If-Match header is required", nil)
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	if strings.Contains(header, ",") {
+		h.logger.Println("If-Match carries several entity tags:", header)
+		h.writeError(c, invalid("If-Match must carry a single entity tag"))
+		return 0, false
+	}
+
+	// Any representation of a version will do, so only the version is
+	// compared. Tags from before the format was added are the bare version.
+	tag, _, _ := strings.Cut(strings.Trim(header, `"`), "-")
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || !strings.HasPrefix(header, `"`) || version < 1 {
+		h.logger.Println("If-Match does not match any version:", header)
+		h.writeProblem(c, http.StatusPreconditionFailed, "If-Match does not match the current version", nil)
+		return 0, false
+	}
+	return version, true
+}
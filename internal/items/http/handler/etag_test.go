@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/http/dto"
+	v1 "github.com/ruziba3vich/boock/internal/items/http/dto/v1"
+	v2 "github.com/ruziba3vich/boock/internal/items/http/dto/v2"
+	"github.com/ruziba3vich/boock/internal/items/http/render"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func testContext(target string, version dto.Version, format *render.Format, header http.Header) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		c.Request.Header[name] = values
+	}
+	c.Set(versionKey, version)
+	c.Set(formatKey, format)
+	return c, w
+}
+
+func TestBookETagPerRepresentation(t *testing.T) {
+	book := &models.Book{BookId: "b1", Version: 3}
+	tests := []struct {
+		target  string
+		version dto.Version
+		format  *render.Format
+		want    string
+	}{
+		{"/books/b1", v1.Version{}, render.JSON, `"3-v1-json"`},
+		{"/books/b1", v2.Version{}, render.JSON, `"3-v2-json"`},
+		{"/books/b1", v1.Version{}, render.XML, `"3-v1-xml"`},
+		{"/books/b1?pretty=1", v1.Version{}, render.JSON, `"3-v1-json-pretty"`},
+		{"/books/b1?pretty=false", v1.Version{}, render.JSON, `"3-v1-json"`},
+	}
+	for _, tt := range tests {
+		c, _ := testContext(tt.target, tt.version, tt.format, nil)
+		if got := bookETag(c, book); got != tt.want {
+			t.Errorf("%s in %s %s: got %s, want %s", tt.target, tt.version.Name(), tt.format.Name, got, tt.want)
+		}
+	}
+}
+
+func TestNotModifiedOnlyForSameRepresentation(t *testing.T) {
+	book := &models.Book{BookId: "b1", Version: 3}
+	header := http.Header{"If-None-Match": {`"3-v1-json"`}}
+
+	c, _ := testContext("/books/b1", v1.Version{}, render.JSON, header)
+	if !notModified(c, bookETag(c, book), book.UpdatedAt) {
+		t.Error("the same representation was not matched")
+	}
+	c, _ = testContext("/books/b1", v1.Version{}, render.XML, header)
+	if notModified(c, bookETag(c, book), book.UpdatedAt) {
+		t.Error("the JSON tag matched the XML representation")
+	}
+	c, _ = testContext("/v2/books/b1", v2.Version{}, render.JSON, header)
+	if notModified(c, bookETag(c, book), book.UpdatedAt) {
+		t.Error("the v1 tag matched the v2 representation")
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	h := &Handler{logger: log.New(io.Discard, "", 0)}
+	tests := []struct {
+		header      string
+		wantVersion int64
+		wantStatus  int
+	}{
+		{`"3-v2-xml"`, 3, http.StatusOK},
+		{`"3-v1-json-pretty"`, 3, http.StatusOK},
+		{`"3"`, 3, http.StatusOK},
+		{"*", 0, http.StatusOK},
+		{"", 0, http.StatusPreconditionRequired},
+		{`W/"3-v1-json"`, 0, http.StatusPreconditionFailed},
+		{`"v1-json"`, 0, http.StatusPreconditionFailed},
+		{`"3-v1-json", "4-v1-json"`, 0, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			c, w := testContext("/books/b1", v1.Version{}, render.JSON, http.Header{"If-Match": {tt.header}})
+			version, ok := h.ifMatchVersion(c)
+			if ok != (tt.wantStatus == http.StatusOK) || version != tt.wantVersion {
+				t.Fatalf("got version %d, ok %v", version, ok)
+			}
+			if !ok && w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
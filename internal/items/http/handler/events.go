@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/events"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// resetEvent tells a client that resumed too late to reload.
+const resetEvent = "reset"
+
+// BookEventsHandler streams book changes as Server-Sent Events. A client
+// that sends Last-Event-ID (or last_event_id, for the first connection of an
+// EventSource) first gets everything it missed from the Redis stream. When
+// that is no longer retained, it gets a reset event instead, and must reload
+// whatever it keeps before applying the events that follow.
+func (h *Handler) BookEventsHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN BookEventsHandler --")
+
+	lastId := c.GetHeader("Last-Event-ID")
+	if lastId == "" {
+		lastId = c.Query("last_event_id")
+	}
+	if lastId != "" && !events.ValidId(lastId) {
+		h.logger.Println("Invalid Last-Event-ID:", lastId)
+		h.writeError(c, invalid("Invalid Last-Event-ID %q", lastId))
+		return
+	}
+
+	// Listen before replaying, so nothing published during the replay is lost.
+	live, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	version := wire(c)
+	send := func(event *models.StreamedBookEvent) error {
+		data, err := json.Marshal(version.Response(event.Event))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Event.Type, data); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	if lastId != "" {
+		var err error
+		lastId, err = h.hub.Replay(ctx, lastId, send)
+		if errors.Is(err, events.ErrGap) {
+			h.logger.Println("Events after Last-Event-ID were trimmed, sending a reset")
+			if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: {\"reason\":%q}\n\n", lastId, resetEvent, events.ErrGap.Error()); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		} else if err != nil {
+			h.logger.Println("Error replaying events:", err)
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.hub.Heartbeat())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-live:
+			if !ok {
+				h.logger.Println("Event listener fell behind, closing the stream")
+				return
+			}
+			if lastId != "" && !events.After(event.Id, lastId) {
+				continue
+			}
+			if err := send(event); err != nil {
+				h.logger.Println("Error writing event:", err)
+				return
+			}
+			lastId = event.Id
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
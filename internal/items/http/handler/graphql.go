@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// GraphQLHandler serves GraphQL over HTTP as a POST with the request as a
+// JSON body.
+func (h *Handler) GraphQLHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN GraphQLHandler --")
+
+	var req models.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Println("Error binding JSON:", err)
+		h.writeError(c, invalid("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	result := h.graphql.Execute(c.Request.Context(), &req, false)
+	h.respond(c, http.StatusOK, result)
+}
+
+// GraphQLQueryHandler serves GraphQL over HTTP as a GET with the request as
+// query parameters, which is how persisted queries are usually sent. It can
+// only run queries.
+func (h *Handler) GraphQLQueryHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN GraphQLQueryHandler --")
+
+	req := models.GraphQLRequest{
+		Query:         c.Query("query"),
+		OperationName: c.Query("operationName"),
+	}
+	for param, target := range map[string]interface{}{"variables": &req.Variables, "extensions": &req.Extensions} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(value), target); err != nil {
+			h.logger.Println("Error parsing", param, "parameter:", err)
+			h.writeError(c, invalid("Invalid %s parameter: %s", param, err.Error()))
+			return
+		}
+	}
+
+	result := h.graphql.Execute(c.Request.Context(), &req, true)
+	h.respond(c, http.StatusOK, result)
+}
@@ -1,69 +1,134 @@
 package handler
 
 import (
-	"context"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/events"
+	"github.com/ruziba3vich/boock/internal/items/gql"
 	"github.com/ruziba3vich/boock/internal/items/repository"
 	"github.com/ruziba3vich/boock/internal/models"
 )
 
 type (
 	Handler struct {
-		service repository.IBookRepo
-		logger  *log.Logger
+		service  repository.IBookRepo
+		auditLog repository.IAuditRepo
+		webhooks repository.IWebhookRepo
+		hub      *events.Hub
+		graphql  *gql.Server
+		logger   *log.Logger
 	}
 )
 
-func New(service repository.IBookRepo, logger *log.Logger) *Handler {
+func New(service repository.IBookRepo, auditLog repository.IAuditRepo, webhooks repository.IWebhookRepo, hub *events.Hub, graphql *gql.Server, logger *log.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:  service,
+		auditLog: auditLog,
+		webhooks: webhooks,
+		hub:      hub,
+		graphql:  graphql,
+		logger:   logger,
 	}
 }
 
 func (h *Handler) CreateBookHandler(c *gin.Context) {
 	h.logger.Println("-- RECEIVED A REQUEST IN CreateBookHandler --")
 
-	var req models.CreateBookRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	body := wire(c).CreateBookRequest()
+	if err := c.ShouldBindJSON(body); err != nil {
 		h.logger.Println("Error binding JSON:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.writeError(c, invalid("Invalid request body: %s", err.Error()))
 		return
 	}
 
-	book, err := h.service.CreateBook(context.Background(), &req)
+	book, err := h.service.CreateBook(c.Request.Context(), body.Model())
 	if err != nil {
 		h.logger.Println("Error creating book:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusCreated, book)
+	c.Header("ETag", bookETag(c, book))
+	h.respond(c, http.StatusCreated, book)
 }
 
 func (h *Handler) UpdateBookHandler(c *gin.Context) {
 	h.logger.Println("-- RECEIVED A REQUEST IN UpdateBookHandler --")
 
-	var req models.UpdateBookRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	version, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	body := wire(c).UpdateBookRequest()
+	if err := c.ShouldBindJSON(body); err != nil {
 		h.logger.Println("Error binding JSON:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.writeError(c, invalid("Invalid request body: %s", err.Error()))
 		return
 	}
+	req := body.Model()
 	req.BookId = c.Param("id")
+	req.ExpectedVersion = version
 
-	book, err := h.service.UpdateBook(context.Background(), &req)
+	book, err := h.service.UpdateBook(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Println("Error updating book:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("ETag", bookETag(c, book))
+	h.respond(c, http.StatusOK, book)
+}
+
+func (h *Handler) PatchBookHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN PatchBookHandler --")
+
+	patchType := c.ContentType()
+	if patchType != models.MergePatchType && patchType != models.JSONPatchType {
+		h.logger.Println("Unsupported patch type:", patchType)
+		c.Header("Accept-Patch", models.MergePatchType+", "+models.JSONPatchType)
+		h.writeProblem(c, http.StatusUnsupportedMediaType, "Content-Type must be "+models.MergePatchType+" or "+models.JSONPatchType, nil)
+		return
+	}
+
+	version, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		h.logger.Println("Error reading patch body:", err)
+		h.writeError(c, invalid("Invalid request body: %s", err.Error()))
+		return
+	}
+	if len(patch) == 0 {
+		h.logger.Println("Patch body is empty")
+		h.writeError(c, invalid("Patch body is required"))
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, book)
+	req := &models.PatchBookRequest{
+		BookId:          c.Param("id"),
+		PatchType:       patchType,
+		Patch:           patch,
+		ExpectedVersion: version,
+		Document:        wire(c).BookDocument(),
+	}
+	book, err := h.service.PatchBook(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error patching book:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("ETag", bookETag(c, book))
+	h.respond(c, http.StatusOK, book)
 }
 
 func (h *Handler) GetBookByIdHandler(c *gin.Context) {
@@ -74,47 +139,60 @@ func (h *Handler) GetBookByIdHandler(c *gin.Context) {
 	req := &models.GetBookByIdRequest{
 		BookId: bookId,
 	}
-	book, err := h.service.GetBookById(context.Background(), req)
+	if asOf := c.Query("as_of"); asOf != "" {
+		t, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			h.logger.Println("Error parsing as_of:", err)
+			h.writeError(c, invalid("Invalid as_of timestamp, expected RFC 3339"))
+			return
+		}
+		req.AsOf = t
+	}
+	book, err := h.service.GetBookById(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Println("Error getting book by ID:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, book)
+	etag := bookETag(c, book)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", book.UpdatedAt.UTC().Format(http.TimeFormat))
+	if notModified(c, etag, book.UpdatedAt) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	h.respond(c, http.StatusOK, book)
 }
 
 func (h *Handler) GetAllBooksHandler(c *gin.Context) {
 	h.logger.Println("-- RECEIVED A REQUEST IN GetAllBooksHandler --")
 
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	page, err := strconv.Atoi(pageStr)
+	paging, err := parseCursorPaging(c)
 	if err != nil {
-		h.logger.Println("Error converting page to int:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		h.logger.Println("Error parsing paging parameters:", err)
+		h.writeError(c, err)
 		return
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		h.logger.Println("Error converting limit to int:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit number"})
+	if h.listNotModified(c) {
 		return
 	}
 
 	req := &models.GetAllBooksRequest{
-		Page: page, Limit: limit,
+		Filter: c.Query("filter"),
+		Sort:   c.Query("sort"),
+		Paging: paging,
 	}
-	response, err := h.service.GetAllBooks(context.Background(), req)
+	response, err := h.service.GetAllBooks(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Println("Error getting all books:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, response)
+	setPaginationLinks(c, response.Pagination)
+	h.respond(c, http.StatusOK, response)
 }
 
 func (h *Handler) GetBooksByAuthorHandler(c *gin.Context) {
@@ -123,21 +201,34 @@ func (h *Handler) GetBooksByAuthorHandler(c *gin.Context) {
 	author := c.Query("author")
 	if author == "" {
 		h.logger.Println("Author query parameter is missing")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Author query parameter is required"})
+		h.writeError(c, invalid("Author query parameter is required"))
+		return
+	}
+
+	paging, err := parseCursorPaging(c)
+	if err != nil {
+		h.logger.Println("Error parsing paging parameters:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	if h.listNotModified(c) {
 		return
 	}
 
 	req := &models.GetBooksByAuthorRequest{
 		Author: author,
+		Paging: paging,
 	}
-	response, err := h.service.GetBooksByAuthor(context.Background(), req)
+	response, err := h.service.GetBooksByAuthor(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Println("Error getting books by author:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, response)
+	setPaginationLinks(c, response.Pagination)
+	h.respond(c, http.StatusOK, response)
 }
 
 func (h *Handler) GetBooksByNameHandler(c *gin.Context) {
@@ -146,41 +237,166 @@ func (h *Handler) GetBooksByNameHandler(c *gin.Context) {
 	name := c.Query("name")
 	if name == "" {
 		h.logger.Println("Name query parameter is missing")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name query parameter is required"})
+		h.writeError(c, invalid("Name query parameter is required"))
+		return
+	}
+
+	paging, err := parseCursorPaging(c)
+	if err != nil {
+		h.logger.Println("Error parsing paging parameters:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	if h.listNotModified(c) {
 		return
 	}
 
 	req := &models.GetBooksByNameRequest{
 		BookName: name,
+		Paging:   paging,
 	}
-	response, err := h.service.GetBooksByName(context.Background(), req)
+	response, err := h.service.GetBooksByName(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Println("Error getting books by name:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, response)
+	setPaginationLinks(c, response.Pagination)
+	h.respond(c, http.StatusOK, response)
 }
 
 func (h *Handler) SearchBooksHandler(c *gin.Context) {
 	h.logger.Println("-- RECEIVED A REQUEST IN SearchBooksHandler --")
 
-	var req models.SearchBooksRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Println("Error binding JSON:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	search := c.Query("search")
+	if search == "" {
+		h.logger.Println("Search query parameter is missing")
+		h.writeError(c, invalid("Search query parameter is required"))
 		return
 	}
 
-	response, err := h.service.SearchBooks(context.Background(), &req)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		h.logger.Println("Error converting limit to int:", err)
+		h.writeError(c, invalid("Invalid limit number"))
+		return
+	}
+
+	if h.listNotModified(c) {
+		return
+	}
+
+	req := &models.SearchBooksRequest{
+		Search: search,
+		Limit:  limit,
+	}
+	response, err := h.service.SearchBooks(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Println("Error searching books:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, response)
+}
+
+func (h *Handler) ListTrashHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN ListTrashHandler --")
+
+	paging, err := parseCursorPaging(c)
+	if err != nil {
+		h.logger.Println("Error parsing paging parameters:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	req := &models.ListTrashRequest{
+		Paging: paging,
+	}
+	response, err := h.service.ListTrash(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error listing trash:", err)
+		h.writeError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, response)
+	setPaginationLinks(c, response.Pagination)
+	h.respond(c, http.StatusOK, response)
+}
+
+func (h *Handler) RestoreBookHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN RestoreBookHandler --")
+
+	req := &models.RestoreBookRequest{
+		BookId: c.Param("id"),
+	}
+	book, err := h.service.RestoreBook(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error restoring book:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("ETag", bookETag(c, book))
+	h.respond(c, http.StatusOK, book)
+}
+
+func (h *Handler) GetBookHistoryHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN GetBookHistoryHandler --")
+
+	paging, err := parsePaging(c)
+	if err != nil {
+		h.logger.Println("Error parsing paging parameters:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	req := &models.GetBookHistoryRequest{
+		BookId: c.Param("id"),
+		Paging: paging,
+	}
+	response, err := h.service.GetBookHistory(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error getting book history:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	setPaginationLinks(c, response.Pagination)
+	h.respond(c, http.StatusOK, response)
+}
+
+func (h *Handler) RevertBookHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN RevertBookHandler --")
+
+	revision, err := strconv.ParseInt(c.Param("revision"), 10, 64)
+	if err != nil || revision <= 0 {
+		h.logger.Println("Error parsing revision:", c.Param("revision"))
+		h.writeError(c, invalid("Invalid revision number"))
+		return
+	}
+
+	version, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	req := &models.RevertBookRequest{
+		BookId:          c.Param("id"),
+		Revision:        revision,
+		ExpectedVersion: version,
+	}
+	book, err := h.service.RevertBook(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error reverting book:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("ETag", bookETag(c, book))
+	h.respond(c, http.StatusOK, book)
 }
 
 func (h *Handler) DeleteBookByIdHandler(c *gin.Context) {
@@ -188,17 +404,23 @@ func (h *Handler) DeleteBookByIdHandler(c *gin.Context) {
 
 	bookId := c.Param("id")
 
+	version, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	req := &models.DeleteBookByIdRequest{
-		BookId: bookId,
+		BookId:          bookId,
+		ExpectedVersion: version,
 	}
-	err := h.service.DeleteBookById(context.Background(), req)
+	err := h.service.DeleteBookById(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Println("Error deleting book by ID:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, gin.H{"message": "Book deleted successfully"})
+	h.respond(c, http.StatusOK, gin.H{"message": "Book deleted successfully"})
 }
 
 /*
@@ -208,6 +430,6 @@ func (h *Handler) DeleteBookByIdHandler(c *gin.Context) {
 	GetAllBooks(context.Context, *models.GetAllBooksRequest) (*models.GetSeveralResponse, error)
 	GetBooksByAuthor(context.Context, *models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error)
 	GetBooksByName(context.Context, *models.GetBooksByNameRequest) (*models.GetSeveralResponse, error)
-	SearchBooks(context.Context, *models.SearchBooksRequest) (*models.GetSeveralResponse, error)
+	SearchBooks(context.Context, *models.SearchBooksRequest) (*models.SearchBooksResponse, error)
 	DeleteBookById(context.Context,*models.DeleteBookByIdRequest) error
 */
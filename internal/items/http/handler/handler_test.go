@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ruziba3vich/boock/internal/items/http/dto"
+	v1 "github.com/ruziba3vich/boock/internal/items/http/dto/v1"
+	v2 "github.com/ruziba3vich/boock/internal/items/http/dto/v2"
+	"github.com/ruziba3vich/boock/internal/items/http/render"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// fakeBooks records the patch it is asked for and returns book.
+type fakeBooks struct {
+	repository.IBookRepo
+	book    *models.Book
+	patched *models.PatchBookRequest
+}
+
+func (f *fakeBooks) PatchBook(_ context.Context, req *models.PatchBookRequest) (*models.Book, error) {
+	f.patched = req
+	return f.book, nil
+}
+
+func TestPatchBookInTheVersionsDocument(t *testing.T) {
+	book := &models.Book{BookId: "b1", Title: "Dune", Version: 4}
+	tests := []struct {
+		version dto.Version
+		want    models.BookDocument
+		idField string
+	}{
+		{v1.Version{}, v1.BookDocument{}, "book_id"},
+		{v2.Version{}, v2.BookDocument{}, "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version.Name(), func(t *testing.T) {
+			service := &fakeBooks{book: book}
+			h := New(service, nil, nil, nil, nil, log.New(io.Discard, "", 0))
+			c, w := testContext("/books/b1", tt.version, render.JSON, http.Header{"If-Match": {`"3"`}})
+			c.Request.Method = http.MethodPatch
+			c.Request.Header.Set("Content-Type", models.MergePatchType)
+			c.Request.Body = io.NopCloser(strings.NewReader(`{"title":"Dune"}`))
+
+			h.PatchBookHandler(c)
+			if w.Code != http.StatusOK {
+				t.Fatalf("got %d: %s", w.Code, w.Body)
+			}
+			if service.patched.Document != tt.want || service.patched.ExpectedVersion != 3 {
+				t.Fatalf("patched %+v, want it in the %s document", service.patched, tt.version.Name())
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body[tt.idField] != "b1" {
+				t.Errorf("got %s, want the id as %s", w.Body, tt.idField)
+			}
+		})
+	}
+}
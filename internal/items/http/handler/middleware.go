@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/boock/internal/pkg/reqmeta"
+)
+
+const (
+	actorHeader     = "X-Actor"
+	requestIdHeader = "X-Request-ID"
+)
+
+// RequestMeta puts the request metadata on the request context, which the
+// handlers pass down to the service. A missing request ID is generated and
+// echoed back so clients can quote it.
+func (h *Handler) RequestMeta(c *gin.Context) {
+	requestId := strings.TrimSpace(c.GetHeader(requestIdHeader))
+	if requestId == "" || len(requestId) > 64 {
+		requestId = uuid.New().String()
+	}
+	c.Header(requestIdHeader, requestId)
+
+	meta := reqmeta.Meta{
+		Actor:     reqmeta.Actor(c.GetHeader(actorHeader)),
+		IP:        c.ClientIP(),
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		RequestId: requestId,
+	}
+	c.Request = c.Request.WithContext(reqmeta.With(c.Request.Context(), meta))
+	c.Next()
+}
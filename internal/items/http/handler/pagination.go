@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func parsePaging(c *gin.Context) (models.Paging, error) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		return models.Paging{}, invalid("Invalid page number")
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		return models.Paging{}, invalid("Invalid limit number")
+	}
+
+	if page < 1 || limit < 1 {
+		return models.Paging{}, invalid("Page and limit must be positive")
+	}
+
+	return models.Paging{
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// parseCursorPaging is parsePaging for the book lists, which can also be
+// paged by cursor.
+func parseCursorPaging(c *gin.Context) (models.Paging, error) {
+	paging, err := parsePaging(c)
+	paging.Cursor = c.Query("cursor")
+	return paging, err
+}
+
+// setPaginationLinks adds an RFC 5988 Link header pointing at the
+// neighbouring pages of the current request. Cursor pages link by cursor,
+// offset pages by page number.
+func setPaginationLinks(c *gin.Context, pagination *models.Pagination) {
+	if pagination == nil {
+		return
+	}
+
+	var links []string
+	if pagination.Page == 0 {
+		if pagination.NextCursor != "" {
+			links = append(links, cursorLink(c, pagination.NextCursor, pagination.Limit, "next"))
+		}
+		if pagination.PrevCursor != "" {
+			links = append(links, cursorLink(c, pagination.PrevCursor, pagination.Limit, "prev"))
+		}
+	} else {
+		if pagination.HasNext {
+			links = append(links, pageLink(c, pagination.Page+1, pagination.Limit, "next"))
+		}
+		if pagination.Page > 1 {
+			links = append(links, pageLink(c, pagination.Page-1, pagination.Limit, "prev"))
+		}
+	}
+	if len(links) > 0 {
+		c.Writer.Header().Add("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageLink(c *gin.Context, page, limit int, rel string) string {
+	return link(c, rel, func(query map[string][]string) {
+		query["page"] = []string{strconv.Itoa(page)}
+		query["limit"] = []string{strconv.Itoa(limit)}
+	})
+}
+
+func cursorLink(c *gin.Context, cursor string, limit int, rel string) string {
+	return link(c, rel, func(query map[string][]string) {
+		delete(query, "page")
+		query["cursor"] = []string{cursor}
+		query["limit"] = []string{strconv.Itoa(limit)}
+	})
+}
+
+func link(c *gin.Context, rel string, update func(map[string][]string)) string {
+	u := *c.Request.URL
+	query := u.Query()
+	update(query)
+	u.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=\"%s\"", u.RequestURI(), rel)
+}
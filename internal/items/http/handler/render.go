@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/http/render"
+)
+
+const formatKey = "format"
+
+// Negotiate picks the response format from Accept before the handler runs,
+// so a request is refused with 406 before it changes anything.
+func (h *Handler) Negotiate(c *gin.Context) {
+	c.Header("Vary", "Accept")
+	format := render.Negotiate(c.GetHeader("Accept"))
+	if format == nil {
+		h.logger.Println("No acceptable format for:", c.GetHeader("Accept"))
+		var offered []string
+		for _, format := range render.Formats {
+			offered = append(offered, format.MediaTypes()[0])
+		}
+		h.writeProblem(c, http.StatusNotAcceptable, "Accept must allow one of "+strings.Join(offered, ", "), nil)
+		return
+	}
+	c.Set(formatKey, format)
+	c.Next()
+}
+
+// negotiated is the format picked for the request. Routes without
+// Negotiate answer in JSON.
+func negotiated(c *gin.Context) *render.Format {
+	if format, ok := c.Get(formatKey); ok {
+		return format.(*render.Format)
+	}
+	return render.JSON
+}
+
+// respond writes value in the wire format of the route and the negotiated
+// format, indented when the query has pretty.
+func (h *Handler) respond(c *gin.Context, status int, value interface{}) {
+	format := negotiated(c)
+	var body bytes.Buffer
+	if err := format.Render(&body, wire(c).Response(value), pretty(c)); err != nil {
+		h.logger.Println("Error rendering", format.Name, "response:", err)
+		h.writeError(c, err)
+		return
+	}
+	c.Data(status, format.ContentType, body.Bytes())
+}
+
+// pretty reports whether the query asks for an indented response.
+func pretty(c *gin.Context) bool {
+	value, ok := c.GetQuery("pretty")
+	return ok && value != "false" && value != "0"
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/http/dto"
+	v1 "github.com/ruziba3vich/boock/internal/items/http/dto/v1"
+)
+
+const versionKey = "api_version"
+
+type (
+	// APIVersion is one set of routes and the wire format they speak.
+	APIVersion struct {
+		// Prefix the routes are served under; empty for the unversioned ones.
+		Prefix      string
+		Wire        dto.Version
+		Deprecation config.Deprecation
+		// Successor is the prefix of the version clients should move to.
+		Successor string
+	}
+)
+
+// Deprecated reports whether clients are told to move off the version.
+func (v APIVersion) Deprecated() bool {
+	return !v.Deprecation.At.IsZero()
+}
+
+// Versioned selects the wire format of the routes it guards. On a deprecated
+// version every response carries the RFC 9745 Deprecation header, the RFC
+// 8594 Sunset header when a date is set, and a link to the same resource in
+// the successor.
+func (h *Handler) Versioned(v APIVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(versionKey, v.Wire)
+		if v.Deprecated() {
+			c.Header("Deprecation", fmt.Sprintf("@%d", v.Deprecation.At.Unix()))
+			if !v.Deprecation.Sunset.IsZero() {
+				c.Header("Sunset", v.Deprecation.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if v.Successor != "" {
+				successor := *c.Request.URL
+				successor.Path = v.Successor + strings.TrimPrefix(successor.Path, v.Prefix)
+				c.Writer.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor.RequestURI()))
+			}
+		}
+		c.Next()
+	}
+}
+
+// wire is the wire format of the route being served. Routes outside a
+// version group speak v1.
+func wire(c *gin.Context) dto.Version {
+	if v, ok := c.Get(versionKey); ok {
+		return v.(dto.Version)
+	}
+	return v1.Version{}
+}
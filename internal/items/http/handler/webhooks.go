@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func (h *Handler) CreateWebhookHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN CreateWebhookHandler --")
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Println("Error binding JSON:", err)
+		h.writeError(c, invalid("Invalid request body: %s", err.Error()))
+		return
+	}
+
+	webhook, err := h.webhooks.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.Println("Error creating webhook:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusCreated, webhook)
+}
+
+func (h *Handler) UpdateWebhookHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN UpdateWebhookHandler --")
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Println("Error binding JSON:", err)
+		h.writeError(c, invalid("Invalid request body: %s", err.Error()))
+		return
+	}
+	req.Id = c.Param("id")
+
+	webhook, err := h.webhooks.UpdateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.Println("Error updating webhook:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, webhook)
+}
+
+func (h *Handler) GetWebhookHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN GetWebhookHandler --")
+
+	webhook, err := h.webhooks.GetWebhook(c.Request.Context(), &models.GetWebhookRequest{Id: c.Param("id")})
+	if err != nil {
+		h.logger.Println("Error getting webhook:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, webhook)
+}
+
+func (h *Handler) ListWebhooksHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN ListWebhooksHandler --")
+
+	response, err := h.webhooks.ListWebhooks(c.Request.Context())
+	if err != nil {
+		h.logger.Println("Error listing webhooks:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, response)
+}
+
+func (h *Handler) DeleteWebhookHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN DeleteWebhookHandler --")
+
+	if err := h.webhooks.DeleteWebhook(c.Request.Context(), &models.DeleteWebhookRequest{Id: c.Param("id")}); err != nil {
+		h.logger.Println("Error deleting webhook:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+func (h *Handler) ListWebhookDeliveriesHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN ListWebhookDeliveriesHandler --")
+
+	paging, err := parsePaging(c)
+	if err != nil {
+		h.logger.Println("Error parsing paging parameters:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	req := &models.ListWebhookDeliveriesRequest{
+		WebhookId: c.Param("id"),
+		Status:    c.Query("status"),
+		Paging:    paging,
+	}
+	response, err := h.webhooks.ListWebhookDeliveries(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error listing webhook deliveries:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	setPaginationLinks(c, response.Pagination)
+	h.respond(c, http.StatusOK, response)
+}
+
+// ReplayWebhookDeliveriesHandler requeues one delivery when the route names
+// it, and every dead delivery of the webhook otherwise.
+func (h *Handler) ReplayWebhookDeliveriesHandler(c *gin.Context) {
+	h.logger.Println("-- RECEIVED A REQUEST IN ReplayWebhookDeliveriesHandler --")
+
+	req := &models.ReplayWebhookDeliveriesRequest{
+		WebhookId: c.Param("id"),
+	}
+	if delivery := c.Param("delivery"); delivery != "" {
+		id, err := strconv.ParseInt(delivery, 10, 64)
+		if err != nil || id <= 0 {
+			h.logger.Println("Error parsing delivery id:", delivery)
+			h.writeError(c, invalid("Invalid delivery id"))
+			return
+		}
+		req.DeliveryId = id
+	}
+
+	response, err := h.webhooks.ReplayWebhookDeliveries(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Println("Error replaying webhook deliveries:", err)
+		h.writeError(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusAccepted, response)
+}
@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check reports every route the router has that the document does not
+// describe, every operation of the document the router does not have, and
+// every route whose path parameters differ from the documented ones. The
+// tests run it, together with CheckResponse, to keep the two from drifting
+// apart.
+func (d *Document) Check(routes gin.RoutesInfo) error {
+	documented := map[string]bool{}
+	for path, item := range d.Paths {
+		for method, op := range item.operations() {
+			if op != nil {
+				documented[method+" "+path] = true
+			}
+		}
+	}
+
+	var problems []string
+	for _, route := range routes {
+		if strings.HasPrefix(route.Path, DocsPath+"/") {
+			continue
+		}
+		path := openAPIPath(route.Path)
+		key := route.Method + " " + path
+		if !documented[key] {
+			problems = append(problems, "undocumented route "+key)
+			continue
+		}
+		delete(documented, key)
+
+		var segments []string
+		for _, segment := range strings.Split(route.Path, "/") {
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				segments = append(segments, segment[1:])
+			}
+		}
+		params := d.Params(d.Operation(route.Method, route.Path), "path")
+		sort.Strings(segments)
+		sort.Strings(params)
+		if strings.Join(segments, ",") != strings.Join(params, ",") {
+			problems = append(problems, fmt.Sprintf("route %s has path parameters %v, documented as %v", key, segments, params))
+		}
+	}
+	for key := range documented {
+		problems = append(problems, "documented route "+key+" is not registered")
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("OpenAPI document does not match the router: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Operation returns the operation documented for method on path, which uses
+// gin syntax, or nil.
+func (d *Document) Operation(method, path string) *Operation {
+	item := d.Paths[openAPIPath(path)]
+	if item == nil {
+		return nil
+	}
+	return item.operations()[method]
+}
+
+// Params returns the names of the parameters of op that are in in, such as
+// "query", following references to the shared ones.
+func (d *Document) Params(op *Operation, in string) []string {
+	if op == nil {
+		return nil
+	}
+	var names []string
+	for _, param := range op.Parameters {
+		if param.Ref != "" {
+			param = d.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+		}
+		if param != nil && param.In == in {
+			names = append(names, param.Name)
+		}
+	}
+	return names
+}
+
+// CheckResponse reports how a response the router gave to method on path
+// differs from the documented responses: an undocumented status or media
+// type, or a JSON body that does not follow its schema. Bodies in other
+// formats are not looked into.
+func (d *Document) CheckResponse(method, path string, status int, contentType string, body []byte) error {
+	op := d.Operation(method, path)
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+	response := op.Responses[statusKey(status)]
+	if response == nil {
+		return fmt.Errorf("%s %s answered %d, which is not documented", method, path, status)
+	}
+	if response.Ref != "" {
+		response = d.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+	}
+
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s answered %d with a body, which is documented as empty", method, path, status)
+		}
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media := response.Content[mediaType]
+	if media == nil {
+		return fmt.Errorf("%s %s answered %d in %q, which is not documented", method, path, status, mediaType)
+	}
+	if mediaType != jsonType && mediaType != problemType {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%s %s answered %d with invalid JSON: %w", method, path, status, err)
+	}
+	if problems := d.validate(media.Schema, value, "$"); len(problems) > 0 {
+		return fmt.Errorf("%s %s answered %d with a body that does not follow the schema: %s", method, path, status, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (item *PathItem) operations() map[string]*Operation {
+	return map[string]*Operation{
+		http.MethodGet:    item.Get,
+		http.MethodPut:    item.Put,
+		http.MethodPost:   item.Post,
+		http.MethodDelete: item.Delete,
+		http.MethodPatch:  item.Patch,
+	}
+}
+
+// validate checks value against the subset of JSON Schema that schemas
+// writes, reporting problems by their JSON path from at. Stricter than JSON
+// Schema, an object with listed properties may not have others, since that
+// is what drift looks like.
+func (d *Document) validate(schema Schema, value interface{}, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = d.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{at + " is not an object"}
+		}
+		var problems []string
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(Schema)
+		required, _ := schema["required"].([]string)
+		for _, name := range required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, at+"."+name+" is missing")
+			}
+		}
+		for name, field := range object {
+			property, ok := properties[name].(Schema)
+			if !ok && additional == nil && properties == nil {
+				continue
+			} else if !ok && additional == nil {
+				problems = append(problems, at+"."+name+" is not documented")
+				continue
+			} else if !ok {
+				property = additional
+			}
+			if field == nil && !slices.Contains(required, name) {
+				continue
+			}
+			problems = append(problems, d.validate(property, field, at+"."+name)...)
+		}
+		sort.Strings(problems)
+		return problems
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{at + " is not an array"}
+		}
+		items, _ := schema["items"].(Schema)
+		var problems []string
+		for i, element := range array {
+			problems = append(problems, d.validate(items, element, at+"["+strconv.Itoa(i)+"]")...)
+		}
+		return problems
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return []string{at + " is not a string"}
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, text); err != nil {
+				return []string{at + " is not a date-time"}
+			}
+		}
+	case "integer":
+		if number, ok := value.(json.Number); !ok {
+			return []string{at + " is not an integer"}
+		} else if _, err := number.Int64(); err != nil {
+			return []string{at + " is not an integer"}
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return []string{at + " is not a number"}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{at + " is not a boolean"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package openapi
+
+type (
+	// Document is the subset of an OpenAPI 3.1 document this API needs.
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Tags       []Tag                `json:"tags,omitempty"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components Components           `json:"components"`
+	}
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+	Tag struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+	PathItem struct {
+		Get    *Operation `json:"get,omitempty"`
+		Put    *Operation `json:"put,omitempty"`
+		Post   *Operation `json:"post,omitempty"`
+		Delete *Operation `json:"delete,omitempty"`
+		Patch  *Operation `json:"patch,omitempty"`
+	}
+	Operation struct {
+		OperationId string               `json:"operationId"`
+		Summary     string               `json:"summary"`
+		Description string               `json:"description,omitempty"`
+		Tags        []string             `json:"tags"`
+		Parameters  []*Parameter         `json:"parameters,omitempty"`
+		RequestBody *RequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*Response `json:"responses"`
+		Deprecated  bool                 `json:"deprecated,omitempty"`
+	}
+	Parameter struct {
+		Ref         string `json:"$ref,omitempty"`
+		Name        string `json:"name,omitempty"`
+		In          string `json:"in,omitempty"`
+		Description string `json:"description,omitempty"`
+		Required    bool   `json:"required,omitempty"`
+		Schema      Schema `json:"schema,omitempty"`
+	}
+	RequestBody struct {
+		Required bool                  `json:"required"`
+		Content  map[string]*MediaType `json:"content"`
+	}
+	Response struct {
+		Ref         string                `json:"$ref,omitempty"`
+		Description string                `json:"description,omitempty"`
+		Headers     map[string]*Header    `json:"headers,omitempty"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+	Header struct {
+		Description string `json:"description,omitempty"`
+		Schema      Schema `json:"schema"`
+	}
+	MediaType struct {
+		Schema Schema `json:"schema"`
+	}
+	Components struct {
+		Schemas    map[string]Schema     `json:"schemas"`
+		Parameters map[string]*Parameter `json:"parameters"`
+		Responses  map[string]*Response  `json:"responses"`
+	}
+
+	// Schema is a JSON Schema 2020-12 object, which OpenAPI 3.1 uses as is.
+	Schema map[string]interface{}
+)
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+func parameters() map[string]*Parameter {
+	return map[string]*Parameter{
+		"Actor":           {Name: "X-Actor", In: "header", Description: "Who is making the request, as recorded in the audit log.", Schema: Schema{"type": "string"}},
+		"RequestId":       {Name: "X-Request-ID", In: "header", Description: "Echoed back; generated when missing.", Schema: Schema{"type": "string", "maxLength": 64}},
+		"IfMatch":         {Name: "If-Match", In: "header", Required: true, Description: "The ETag of the version the write is based on.", Schema: Schema{"type": "string"}},
+		"IfNoneMatch":     {Name: "If-None-Match", In: "header", Schema: Schema{"type": "string"}},
+		"IfModifiedSince": {Name: "If-Modified-Since", In: "header", Schema: Schema{"type": "string"}},
+		"Page":            {Name: "page", In: "query", Schema: Schema{"type": "integer", "minimum": 1, "default": 1}},
+		"Limit":           {Name: "limit", In: "query", Schema: Schema{"type": "integer", "minimum": 1, "default": 10}},
+		"Pretty":          {Name: "pretty", In: "query", Description: "Indent the response, in formats that can be.", Schema: Schema{"type": "boolean"}},
+		"Cursor":          {Name: "cursor", In: "query", Description: "A cursor from a Link header; page is ignored when it is set.", Schema: Schema{"type": "string"}},
+	}
+}
+
+// bookParams is params, behind the headers any request may carry.
+func bookParams(params ...*Parameter) []*Parameter {
+	return append([]*Parameter{ref("Actor"), ref("RequestId")}, params...)
+}
+
+func ref(name string) *Parameter {
+	return &Parameter{Ref: "#/components/parameters/" + name}
+}
+
+func pathParam(name string) *Parameter {
+	return &Parameter{Name: name, In: "path", Required: true, Schema: Schema{"type": "string"}}
+}
+
+func query(name string, schema Schema, description string) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func requiredQuery(name string, schema Schema, description string) *Parameter {
+	p := query(name, schema, description)
+	p.Required = true
+	return p
+}
+
+func header(name, description string) *Parameter {
+	return &Parameter{Name: name, In: "header", Description: description, Schema: Schema{"type": "string"}}
+}
+
+func jsonBody(schema Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{jsonType: {Schema: schema}}}
+}
+
+func jsonResponse(description string, schema Schema, headers ...map[string]*Header) *Response {
+	response := &Response{Description: description, Content: map[string]*MediaType{jsonType: {Schema: schema}}}
+	for _, h := range headers {
+		if response.Headers == nil {
+			response.Headers = map[string]*Header{}
+		}
+		for name, value := range h {
+			response.Headers[name] = value
+		}
+	}
+	return response
+}
+
+func etagHeader() map[string]*Header {
+	return map[string]*Header{"ETag": {Description: "The version of the book in this representation. The ETag of any representation of a version works for If-Match.", Schema: Schema{"type": "string"}}}
+}
+
+func linkHeader() map[string]*Header {
+	return map[string]*Header{"Link": {Description: "RFC 8288 links to the neighbouring pages.", Schema: Schema{"type": "string"}}}
+}
+
+func graphQLResponse() *Response {
+	return jsonResponse("The GraphQL result. Errors of the query itself are reported here, not by status.", Schema{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data":       Schema{},
+			"errors":     Schema{"type": "array", "items": Schema{"type": "object"}},
+			"extensions": Schema{"type": "object"},
+		},
+	})
+}
+
+func statusKey(status int) string {
+	return strconv.Itoa(status)
+}
+
+// openAPIPath turns gin's /books/:id into /books/{id}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
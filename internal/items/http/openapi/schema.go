@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	versionPackage = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// schemas builds component schemas from Go types the way encoding/json
+// would render them, so the spec follows the models without being written by
+// hand.
+type schemas struct {
+	components map[string]Schema
+}
+
+// ref returns a reference to the schema of v's type, adding it and every
+// named type it uses to the components.
+func (s *schemas) ref(v interface{}) Schema {
+	return s.of(reflect.TypeOf(v))
+}
+
+func (s *schemas) of(t reflect.Type) Schema {
+	switch t {
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.of(t.Elem())
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Schema{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Interface:
+		// Any JSON value.
+		return Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		name := componentName(t)
+		if _, ok := s.components[name]; !ok {
+			// Reserve the name first so recursive types terminate.
+			s.components[name] = nil
+			s.components[name] = s.object(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + name}
+	}
+	return Schema{}
+}
+
+// componentName is the name of a named type. The wire types of an API
+// version share names with each other, so they are prefixed with it, as in
+// V2Book. A generic type such as v1.BookList[*v2.Book] belongs to the version
+// of its type argument: V2BookList.
+func componentName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pkgPath := t.PkgPath()
+	name, argument, generic := strings.Cut(t.Name(), "[")
+	if generic {
+		argument = strings.TrimLeft(strings.TrimSuffix(argument, "]"), "*")
+		pkgPath = argument[:strings.LastIndex(argument, ".")]
+	}
+	if pkg := path.Base(pkgPath); versionPackage.MatchString(pkg) {
+		return strings.ToUpper(pkg) + name
+	}
+	return name
+}
+
+func (s *schemas) object(t reflect.Type) Schema {
+	properties := map[string]interface{}{}
+	required := []string{}
+	s.fields(t, properties, &required)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fields collects the JSON properties of struct t. Embedded structs without
+// a tag are flattened, as encoding/json does. Properties that are always
+// written are required.
+func (s *schemas) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.fields(field.Type, properties, required)
+			continue
+		}
+		if field.Type.Kind() == reflect.Func || field.Type.Kind() == reflect.Chan {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = s.of(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// body returns a reference to the schema of request body v. Which fields a
+// client must send is up to the service, not the Go type, so the caller
+// names them.
+func (s *schemas) body(v interface{}, required ...string) Schema {
+	ref := s.ref(v)
+	schema := s.components[componentName(reflect.TypeOf(v))]
+	delete(schema, "required")
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return ref
+}
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// DocsPath is where Swagger UI is served. Its own routes are not part of the
+// document.
+const DocsPath = "/docs"
+
+// swaggerInitializer replaces the one shipped with Swagger UI, which points
+// at the petstore.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// Serve returns a handler that writes the document. It is encoded once, up
+// front, since it never changes while the process runs.
+func (d *Document) Serve() (gin.HandlerFunc, error) {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", body)
+	}, nil
+}
+
+// SwaggerUI serves the embedded Swagger UI, pointed at specURL. It must be
+// registered on DocsPath + "/*any".
+func SwaggerUI(specURL string) gin.HandlerFunc {
+	files := http.FileServer(http.FS(swaggerFiles.FS))
+	initializer := fmt.Sprintf(swaggerInitializer, specURL)
+
+	return func(c *gin.Context) {
+		switch file := c.Param("any"); file {
+		case "":
+			// The UI loads its assets relative to the page.
+			c.Redirect(http.StatusMovedPermanently, DocsPath+"/")
+			return
+		case "/":
+			c.Request.URL.Path = "/"
+		case "/swagger-initializer.js":
+			c.Data(http.StatusOK, "application/javascript", []byte(initializer))
+			return
+		default:
+			c.Request.URL.Path = file
+		}
+		files.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,445 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ruziba3vich/boock/internal/items/http/dto"
+	"github.com/ruziba3vich/boock/internal/items/http/handler"
+	"github.com/ruziba3vich/boock/internal/items/http/render"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+const (
+	jsonType        = "application/json"
+	problemType     = "application/problem+json"
+	eventStreamType = "text/event-stream"
+)
+
+// route is one entry of the route table the document is built from. Path
+// uses gin syntax, so it can be compared with the routes the router has.
+type route struct {
+	method      string
+	path        string
+	id          string
+	summary     string
+	description string
+	tag         string
+	params      []*Parameter
+	body        *RequestBody
+	responses   map[int]*Response
+	errors      []int
+	deprecated  bool
+}
+
+// New builds the OpenAPI document of the HTTP API, with the versioned routes
+// once for each of versions.
+func New(versions []handler.APIVersion) *Document {
+	s := &schemas{components: map[string]Schema{}}
+
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:   "Books API",
+			Version: "1.0.0",
+			Description: "Errors are RFC 7807 problem details. Writes to a book need the ETag it was read with in If-Match. " +
+				"Routes are served under /v1 and /v2, and unversioned as v1; deprecated ones answer with Deprecation and Sunset headers. " +
+				"Responses are JSON unless Accept asks for CSV, XML, YAML or MessagePack.",
+		},
+		Tags: []Tag{
+			{Name: "books", Description: "The catalog, its trash and the history of every book."},
+			{Name: "webhooks", Description: "Subscriptions to book changes and their deliveries."},
+			{Name: "audit", Description: "The hash chained log of every mutating request."},
+			{Name: "graphql", Description: "The catalog as a GraphQL schema."},
+			{Name: "meta", Description: "This document and the runtime counters."},
+		},
+		Paths: map[string]*PathItem{},
+		Components: Components{
+			Schemas:    s.components,
+			Parameters: parameters(),
+			Responses: map[string]*Response{
+				"Problem": {
+					Description: "The request failed.",
+					Content:     map[string]*MediaType{problemType: {Schema: s.ref(handler.Problem{})}},
+				},
+			},
+		},
+	}
+
+	for _, version := range versions {
+		for _, r := range versionedRoutes(s, version.Wire) {
+			if version.Prefix != "" {
+				r.path = version.Prefix + r.path
+				r.id = version.Wire.Name() + strings.ToUpper(r.id[:1]) + r.id[1:]
+			}
+			r.deprecated = version.Deprecated()
+			negotiate(r)
+			doc.add(r)
+		}
+	}
+	for _, r := range routes(s) {
+		doc.add(r)
+	}
+	return doc
+}
+
+// negotiate documents the formats besides JSON a route with JSON responses
+// answers in, as picked by Accept.
+func negotiate(r *route) {
+	negotiated := false
+	for _, response := range r.responses {
+		media, ok := response.Content[jsonType]
+		if !ok {
+			continue
+		}
+		negotiated = true
+		for _, format := range render.Formats {
+			mediaType := format.MediaTypes()[0]
+			if _, ok := response.Content[mediaType]; ok {
+				continue
+			}
+			if format == render.CSV {
+				response.Content[mediaType] = &MediaType{Schema: Schema{"type": "string", "description": "One row per element of the list, under a header row."}}
+			} else {
+				response.Content[mediaType] = media
+			}
+		}
+	}
+	if negotiated {
+		r.params = append(r.params, ref("Pretty"))
+		r.errors = append(r.errors, http.StatusNotAcceptable)
+	}
+}
+
+func (d *Document) add(r *route) {
+	op := &Operation{
+		OperationId: r.id,
+		Summary:     r.summary,
+		Description: r.description,
+		Tags:        []string{r.tag},
+		Parameters:  r.params,
+		RequestBody: r.body,
+		Responses:   map[string]*Response{},
+		Deprecated:  r.deprecated,
+	}
+	for status, response := range r.responses {
+		op.Responses[statusKey(status)] = response
+	}
+	for _, status := range r.errors {
+		op.Responses[statusKey(status)] = &Response{Ref: "#/components/responses/Problem"}
+	}
+	for _, segment := range strings.Split(r.path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			op.Parameters = append(op.Parameters, pathParam(segment[1:]))
+		}
+	}
+
+	path := openAPIPath(r.path)
+	item := d.Paths[path]
+	if item == nil {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	switch r.method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodPatch:
+		item.Patch = op
+	}
+}
+
+// versionedRoutes are the routes served under every version prefix, with
+// the types of wire.
+func versionedRoutes(s *schemas, wire dto.Version) []*route {
+	book := jsonResponse("The book.", s.ref(wire.Response(&models.Book{})), etagHeader())
+	books := jsonResponse("One page of books.", s.ref(wire.Response(&models.GetSeveralResponse{})), linkHeader())
+	message := jsonResponse("Done.", Schema{
+		"type":       "object",
+		"properties": map[string]interface{}{"message": Schema{"type": "string"}},
+		"required":   []string{"message"},
+	})
+	notModified := &Response{Description: "The client's copy is still current."}
+
+	listParams := func(params ...*Parameter) []*Parameter {
+		return bookParams(append(params, ref("Page"), ref("Limit"), ref("Cursor"), ref("IfNoneMatch"), ref("IfModifiedSince"))...)
+	}
+
+	return []*route{
+		{
+			method: http.MethodPost, path: "/books", id: "createBook", tag: "books",
+			summary: "Create a book",
+			params:  bookParams(),
+			body:    jsonBody(s.body(wire.CreateBookRequest(), "title", "author", "published_year")),
+			responses: map[int]*Response{
+				http.StatusCreated: jsonResponse("The created book.", s.ref(wire.Response(&models.Book{})), etagHeader()),
+			},
+			errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+		},
+		{
+			method: http.MethodPut, path: "/books/:id", id: "updateBook", tag: "books",
+			summary:   "Replace a book",
+			params:    bookParams(ref("IfMatch")),
+			body:      jsonBody(s.body(wire.UpdateBookRequest(), "title", "author", "published_year")),
+			responses: map[int]*Response{http.StatusOK: book},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired},
+		},
+		{
+			method: http.MethodPatch, path: "/books/:id", id: "patchBook", tag: "books",
+			summary: "Patch a book",
+			params:  bookParams(ref("IfMatch")),
+			body: &RequestBody{
+				Required: true,
+				Content: map[string]*MediaType{
+					models.MergePatchType: {Schema: Schema{"type": "object", "description": "An RFC 7396 merge patch of the book."}},
+					models.JSONPatchType: {Schema: Schema{
+						"type":        "array",
+						"description": "RFC 6902 operations on the book.",
+						"items": Schema{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"op":    Schema{"type": "string", "enum": []string{"add", "remove", "replace", "move", "copy", "test"}},
+								"path":  Schema{"type": "string"},
+								"from":  Schema{"type": "string"},
+								"value": Schema{},
+							},
+							"required": []string{"op", "path"},
+						},
+					}},
+				},
+			},
+			responses: map[int]*Response{http.StatusOK: book},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity, http.StatusPreconditionRequired},
+		},
+		{
+			method: http.MethodGet, path: "/books/:id", id: "getBook", tag: "books",
+			summary: "Get a book",
+			params: bookParams(
+				query("as_of", Schema{"type": "string", "format": "date-time"}, "Return the book as it was at this moment."),
+				ref("IfNoneMatch"), ref("IfModifiedSince"),
+			),
+			responses: map[int]*Response{http.StatusOK: book, http.StatusNotModified: notModified},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			method: http.MethodDelete, path: "/books/:id", id: "deleteBook", tag: "books",
+			summary:     "Move a book to the trash",
+			description: "The book can be restored until it is purged.",
+			params:      bookParams(ref("IfMatch")),
+			responses:   map[int]*Response{http.StatusOK: message},
+			errors:      []int{http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired},
+		},
+		{
+			method: http.MethodGet, path: "/books/all", id: "listBooks", tag: "books",
+			summary: "List books",
+			params: listParams(
+				query("filter", Schema{"type": "string"}, "Comma separated field:operator:value terms, such as `author:eq:Tolstoy,published_year:gte:1860`. `in` takes values separated by `|`. A backslash escapes the next character, so `\\,` and `\\|` stand for a comma and a bar in a value. `like` works on text fields only, and `book_id` values must be UUIDs."),
+				query("sort", Schema{"type": "string"}, "Comma separated fields, such as `-published_year`; a leading `-` sorts descending."),
+			),
+			responses: map[int]*Response{http.StatusOK: books, http.StatusNotModified: notModified},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodGet, path: "/books/author", id: "listBooksByAuthor", tag: "books",
+			summary:   "List books by author",
+			params:    listParams(requiredQuery("author", Schema{"type": "string"}, "")),
+			responses: map[int]*Response{http.StatusOK: books, http.StatusNotModified: notModified},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodGet, path: "/books/name", id: "listBooksByTitle", tag: "books",
+			summary:   "List books by title",
+			params:    listParams(requiredQuery("name", Schema{"type": "string"}, "")),
+			responses: map[int]*Response{http.StatusOK: books, http.StatusNotModified: notModified},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodGet, path: "/books/search", id: "searchBooks", tag: "books",
+			summary: "Search books",
+			params: bookParams(
+				requiredQuery("search", Schema{"type": "string"}, "Full text query over title and author."),
+				query("limit", Schema{"type": "integer", "minimum": 1, "default": 10}, ""),
+				ref("IfNoneMatch"), ref("IfModifiedSince"),
+			),
+			responses: map[int]*Response{
+				http.StatusOK:          jsonResponse("Matches, best first.", s.ref(wire.Response(&models.SearchBooksResponse{}))),
+				http.StatusNotModified: notModified,
+			},
+			errors: []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodGet, path: "/books/trash", id: "listTrash", tag: "books",
+			summary:   "List books in the trash",
+			params:    listParams(),
+			responses: map[int]*Response{http.StatusOK: books, http.StatusNotModified: notModified},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodGet, path: "/books/events", id: "streamBookEvents", tag: "books",
+			summary:     "Stream book changes",
+			description: "Server-Sent Events. Every event has the type of the change as its name and a BookEvent as its data. A client that resumes after events were trimmed from the stream first gets a `reset` event, and must reload what it keeps.",
+			params: bookParams(
+				header("Last-Event-ID", "Resume after this event."),
+				query("last_event_id", Schema{"type": "string"}, "Same as Last-Event-ID, for clients that cannot set headers."),
+			),
+			responses: map[int]*Response{
+				http.StatusOK: {
+					Description: "The event stream.",
+					Content:     map[string]*MediaType{eventStreamType: {Schema: s.ref(wire.Response(&models.BookEvent{}))}},
+				},
+			},
+			errors: []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodPost, path: "/books/:id/restore", id: "restoreBook", tag: "books",
+			summary:   "Restore a book from the trash",
+			params:    bookParams(),
+			responses: map[int]*Response{http.StatusOK: book},
+			errors:    []int{http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			method: http.MethodGet, path: "/books/:id/history", id: "getBookHistory", tag: "books",
+			summary: "List the revisions of a book",
+			params:  bookParams(ref("Page"), ref("Limit")),
+			responses: map[int]*Response{
+				http.StatusOK: jsonResponse("Revisions, newest first.", s.ref(wire.Response(&models.GetBookHistoryResponse{})), linkHeader()),
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			method: http.MethodPost, path: "/books/:id/revert/:revision", id: "revertBook", tag: "books",
+			summary:     "Revert a book to an earlier revision",
+			description: "Writes the content of the revision as a new version; history is kept.",
+			params:      bookParams(ref("IfMatch")),
+			responses:   map[int]*Response{http.StatusOK: book},
+			errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired},
+		},
+		{
+			method: http.MethodPost, path: "/webhooks", id: "createWebhook", tag: "webhooks",
+			summary: "Subscribe a webhook",
+			params:  bookParams(),
+			body:    jsonBody(s.body(models.CreateWebhookRequest{}, "url")),
+			responses: map[int]*Response{
+				http.StatusCreated: jsonResponse("The webhook, with its secret.", s.ref(models.Webhook{})),
+			},
+			errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		},
+		{
+			method: http.MethodGet, path: "/webhooks", id: "listWebhooks", tag: "webhooks",
+			summary:   "List webhooks",
+			params:    bookParams(),
+			responses: map[int]*Response{http.StatusOK: jsonResponse("Every webhook.", s.ref(models.ListWebhooksResponse{}))},
+		},
+		{
+			method: http.MethodGet, path: "/webhooks/:id", id: "getWebhook", tag: "webhooks",
+			summary:   "Get a webhook",
+			params:    bookParams(),
+			responses: map[int]*Response{http.StatusOK: jsonResponse("The webhook.", s.ref(models.Webhook{}))},
+			errors:    []int{http.StatusNotFound},
+		},
+		{
+			method: http.MethodPut, path: "/webhooks/:id", id: "updateWebhook", tag: "webhooks",
+			summary:   "Replace a webhook",
+			params:    bookParams(),
+			body:      jsonBody(s.body(models.UpdateWebhookRequest{}, "url")),
+			responses: map[int]*Response{http.StatusOK: jsonResponse("The webhook.", s.ref(models.Webhook{}))},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+		},
+		{
+			method: http.MethodDelete, path: "/webhooks/:id", id: "deleteWebhook", tag: "webhooks",
+			summary:   "Delete a webhook and its deliveries",
+			params:    bookParams(),
+			responses: map[int]*Response{http.StatusOK: message},
+			errors:    []int{http.StatusNotFound},
+		},
+		{
+			method: http.MethodGet, path: "/webhooks/:id/deliveries", id: "listWebhookDeliveries", tag: "webhooks",
+			summary: "List the deliveries of a webhook",
+			params: bookParams(
+				query("status", Schema{"type": "string", "enum": []string{models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead}}, ""),
+				ref("Page"), ref("Limit"),
+			),
+			responses: map[int]*Response{
+				http.StatusOK: jsonResponse("Deliveries, newest first.", s.ref(models.ListWebhookDeliveriesResponse{}), linkHeader()),
+			},
+			errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			method: http.MethodPost, path: "/webhooks/:id/deliveries/replay", id: "replayWebhookDeliveries", tag: "webhooks",
+			summary:   "Replay every dead delivery of a webhook",
+			params:    bookParams(),
+			responses: map[int]*Response{http.StatusAccepted: jsonResponse("The deliveries were queued again.", s.ref(models.ReplayWebhookDeliveriesResponse{}))},
+			errors:    []int{http.StatusNotFound},
+		},
+		{
+			method: http.MethodPost, path: "/webhooks/:id/deliveries/:delivery/replay", id: "replayWebhookDelivery", tag: "webhooks",
+			summary:   "Replay one delivery",
+			params:    bookParams(),
+			responses: map[int]*Response{http.StatusAccepted: jsonResponse("The delivery was queued again.", s.ref(models.ReplayWebhookDeliveriesResponse{}))},
+			errors:    []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			method: http.MethodGet, path: "/audit", id: "queryAuditLog", tag: "audit",
+			summary: "Query the audit log",
+			params: bookParams(
+				query("actor", Schema{"type": "string"}, ""),
+				query("from", Schema{"type": "string", "format": "date-time"}, "Only entries at or after this moment."),
+				query("to", Schema{"type": "string", "format": "date-time"}, "Only entries before this moment."),
+				ref("Page"), ref("Limit"),
+			),
+			responses: map[int]*Response{
+				http.StatusOK: jsonResponse("Entries, newest first.", s.ref(models.QueryAuditLogResponse{}), linkHeader()),
+			},
+			errors: []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodGet, path: "/audit/verify", id: "verifyAuditLog", tag: "audit",
+			summary:   "Verify the hash chain of the audit log",
+			params:    bookParams(),
+			responses: map[int]*Response{http.StatusOK: jsonResponse("The outcome.", s.ref(models.VerifyAuditLogResponse{}))},
+		},
+	}
+}
+
+// routes are the routes outside the versions.
+func routes(s *schemas) []*route {
+	return []*route{
+		{
+			method: http.MethodGet, path: "/graphql", id: "graphqlQuery", tag: "graphql",
+			summary: "Run a GraphQL query",
+			params: bookParams(
+				query("query", Schema{"type": "string"}, "May be left out when extensions names a persisted query."),
+				query("operationName", Schema{"type": "string"}, ""),
+				query("variables", Schema{"type": "string"}, "JSON encoded variables."),
+				query("extensions", Schema{"type": "string"}, "JSON encoded extensions, such as persistedQuery."),
+				ref("Pretty"),
+			),
+			responses: map[int]*Response{http.StatusOK: graphQLResponse()},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodPost, path: "/graphql", id: "graphqlRequest", tag: "graphql",
+			summary:   "Run a GraphQL query or mutation",
+			params:    bookParams(ref("Pretty")),
+			body:      jsonBody(s.body(models.GraphQLRequest{})),
+			responses: map[int]*Response{http.StatusOK: graphQLResponse()},
+			errors:    []int{http.StatusBadRequest},
+		},
+		{
+			method: http.MethodGet, path: "/debug/vars", id: "getDebugVars", tag: "meta",
+			summary:     "Runtime and cache counters",
+			description: "expvar output. `cache` holds hits and misses per query type, such as `search_hits`.",
+			responses:   map[int]*Response{http.StatusOK: jsonResponse("The counters.", Schema{"type": "object"})},
+		},
+		{
+			method: http.MethodGet, path: "/openapi.json", id: "getOpenAPI", tag: "meta",
+			summary:   "This document",
+			responses: map[int]*Response{http.StatusOK: jsonResponse("The OpenAPI document.", Schema{"type": "object"})},
+		},
+	}
+}
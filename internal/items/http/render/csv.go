@@ -0,0 +1,110 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// encodeCSV writes one row per element of the first list in value, such as
+// the books of a page, under a header row. Anything else is a single row.
+// Nested objects become dotted columns, like book.title, and nested lists a
+// JSON cell.
+func encodeCSV(w io.Writer, value interface{}, _ bool) error {
+	t, err := tree(value)
+	if err != nil {
+		return err
+	}
+
+	var (
+		columns []string
+		seen    = map[string]bool{}
+		rows    []map[string]string
+	)
+	for _, record := range records(t) {
+		row := map[string]string{}
+		if err := flatten("", record, func(column, cell string) {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+			row[column] = cell
+		}); err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+
+	writer := csv.NewWriter(w)
+	if len(columns) > 0 {
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func records(t interface{}) []interface{} {
+	switch t := t.(type) {
+	case []interface{}:
+		return t
+	case object:
+		for _, m := range t {
+			if list, ok := m.value.([]interface{}); ok {
+				return list
+			}
+		}
+	}
+	return []interface{}{t}
+}
+
+func flatten(prefix string, value interface{}, cell func(column, cell string)) error {
+	switch value := value.(type) {
+	case object:
+		for _, m := range value {
+			if err := flatten(prefix+m.key+".", m.value, cell); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		cell(column(prefix), string(data))
+		return nil
+	case string:
+		cell(column(prefix), defuse(value))
+		return nil
+	}
+	cell(column(prefix), scalar(value))
+	return nil
+}
+
+func column(prefix string) string {
+	if prefix == "" {
+		return "value"
+	}
+	return strings.TrimSuffix(prefix, ".")
+}
+
+// defuse keeps spreadsheets from running text that looks like a formula,
+// since titles and authors come from clients.
+func defuse(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ugorji/go/codec"
+)
+
+var msgpackHandle = &codec.MsgpackHandle{WriteExt: true}
+
+func encodeMessagePack(w io.Writer, value interface{}, _ bool) error {
+	t, err := tree(value)
+	if err != nil {
+		return err
+	}
+	return codec.NewEncoder(w, msgpackHandle).Encode(plain(t))
+}
+
+// plain turns a tree into maps and native numbers, which MessagePack has
+// types for. Integers stay integers.
+func plain(value interface{}) interface{} {
+	switch value := value.(type) {
+	case object:
+		m := make(map[string]interface{}, len(value))
+		for _, member := range value {
+			m[member.key] = plain(member.value)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			list[i] = plain(item)
+		}
+		return list
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	}
+	return value
+}
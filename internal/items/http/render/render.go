@@ -0,0 +1,173 @@
+// Package render writes response bodies in the format a client asked for
+// with Accept. Every format is derived from the JSON encoding of a value, so
+// field names and omitted fields are the same in all of them.
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	Format struct {
+		// Name is a short name of the format, such as "csv".
+		Name string
+		// ContentType labels responses in the format.
+		ContentType string
+		// mediaTypes are what Accept can ask for the format by.
+		mediaTypes []string
+		encode     func(w io.Writer, value interface{}, pretty bool) error
+	}
+
+	mediaRange struct {
+		mediaType string
+		q         float64
+	}
+)
+
+var (
+	JSON = &Format{
+		Name:        "json",
+		ContentType: "application/json; charset=utf-8",
+		mediaTypes:  []string{"application/json"},
+		encode:      encodeJSON,
+	}
+	CSV = &Format{
+		Name:        "csv",
+		ContentType: "text/csv; charset=utf-8; header=present",
+		mediaTypes:  []string{"text/csv"},
+		encode:      encodeCSV,
+	}
+	XML = &Format{
+		Name:        "xml",
+		ContentType: "application/xml; charset=utf-8",
+		mediaTypes:  []string{"application/xml", "text/xml"},
+		encode:      encodeXML,
+	}
+	YAML = &Format{
+		Name:        "yaml",
+		ContentType: "application/yaml; charset=utf-8",
+		mediaTypes:  []string{"application/yaml", "application/x-yaml", "text/yaml"},
+		encode:      encodeYAML,
+	}
+	MessagePack = &Format{
+		Name:        "msgpack",
+		ContentType: "application/msgpack",
+		mediaTypes:  []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+		encode:      encodeMessagePack,
+	}
+
+	// Formats in the order a wildcard in Accept picks them.
+	Formats = []*Format{JSON, CSV, XML, YAML, MessagePack}
+)
+
+// MediaTypes are the types Accept can ask for the format by, canonical first.
+func (f *Format) MediaTypes() []string {
+	return f.mediaTypes
+}
+
+// Render writes value in the format. Pretty asks for indentation where the
+// format has any.
+func (f *Format) Render(w io.Writer, value interface{}, pretty bool) error {
+	return f.encode(w, value, pretty)
+}
+
+// Negotiate picks the format for an Accept header following RFC 9110:
+// higher q wins, a specific type beats a wildcard, and q=0 rules a type out.
+// A missing header means JSON; nil means the client takes none of the
+// formats.
+func Negotiate(accept string) *Format {
+	if strings.TrimSpace(accept) == "" {
+		return JSON
+	}
+
+	ranges := parseAccept(accept)
+	for _, r := range ranges {
+		if r.q <= 0 {
+			continue
+		}
+		for _, format := range Formats {
+			if r.matches(format) && !refused(ranges, format) {
+				return format
+			}
+		}
+	}
+	return nil
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		if r.mediaType == "" {
+			continue
+		}
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return strings.Count(ranges[i].mediaType, "*") < strings.Count(ranges[j].mediaType, "*")
+	})
+	return ranges
+}
+
+func (r mediaRange) matches(format *Format) bool {
+	if r.mediaType == "*/*" {
+		return true
+	}
+	for _, mediaType := range format.mediaTypes {
+		if r.mediaType == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(r.mediaType, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// refused reports whether Accept names one of the types of format with q=0.
+func refused(ranges []mediaRange, format *Format) bool {
+	for _, r := range ranges {
+		if r.q > 0 {
+			continue
+		}
+		for _, mediaType := range format.mediaTypes {
+			if r.mediaType == mediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func encodeJSON(w io.Writer, value interface{}, pretty bool) error {
+	var (
+		data []byte
+		err  error
+	)
+	if pretty {
+		data, err = json.MarshalIndent(value, "", "    ")
+	} else {
+		data, err = json.Marshal(value)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
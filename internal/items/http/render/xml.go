@@ -0,0 +1,71 @@
+package render
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+)
+
+// xmlName is the subset of XML names a JSON key is written as directly.
+var xmlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// encodeXML writes value as a <response> element. Object members become
+// child elements, list elements become <item> elements and null an empty
+// element. A key that is not a valid XML name is written as
+// <entry key="...">.
+func encodeXML(w io.Writer, value interface{}, pretty bool) error {
+	t, err := tree(value)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	if pretty {
+		encoder.Indent("", "  ")
+	}
+	if err := writeElement(encoder, "response", t); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func writeElement(encoder *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !xmlName.MatchString(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+
+	switch value := value.(type) {
+	case object:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, m := range value {
+			if err := writeElement(encoder, m.key, m.value); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case []interface{}:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range value {
+			if err := writeElement(encoder, "item", item); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case nil:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(start.End())
+	}
+	return encoder.EncodeElement(scalar(value), start)
+}
@@ -0,0 +1,147 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+const (
+	// baseBackoff is the wait after the first failed attempt; it doubles
+	// with every further attempt up to the configured maximum.
+	baseBackoff = time.Second
+	// sweepBatch is how many published events one delete removes.
+	sweepBatch = 1000
+)
+
+type (
+	// Relay moves events from the outbox to the Redis stream, and deletes
+	// them from the outbox once they have been published for Retention.
+	Relay struct {
+		outbox    repository.IOutboxRepo
+		publisher Publisher
+		cfg       config.OutboxConfig
+		channel   string
+		logger    *log.Logger
+		// swept is when the outbox was last swept.
+		swept time.Time
+	}
+
+	// Publisher appends an event to a stream and announces it on a channel,
+	// as *redisservice.RedisService does.
+	Publisher interface {
+		PublishEvent(ctx context.Context, stream, channel string, maxLen int64, event *models.BookEvent) (string, error)
+	}
+)
+
+// New builds a relay that also announces every event on channel, for the
+// live listeners of the events hub.
+func New(outbox repository.IOutboxRepo, publisher Publisher, cfg config.OutboxConfig, channel string, logger *log.Logger) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		publisher: publisher,
+		cfg:       cfg,
+		channel:   channel,
+		logger:    logger,
+	}
+}
+
+// Run relays until ctx is done. Several relays may run at once; each event
+// is leased to one of them at a time.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if time.Since(r.swept) >= r.cfg.SweepInterval {
+			r.sweep(ctx)
+		}
+		// A full batch means more events are probably waiting.
+		if r.relayBatch(ctx) == r.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch publishes one batch of due events and returns how many it
+// claimed.
+func (r *Relay) relayBatch(ctx context.Context) int {
+	events, err := r.outbox.ClaimOutboxEvents(ctx, &models.ClaimOutboxEventsRequest{
+		Limit: r.cfg.BatchSize,
+		Lease: r.cfg.Lease,
+	})
+	if err != nil {
+		r.logger.Println("Error claiming outbox events:", err)
+		return 0
+	}
+
+	for _, event := range events {
+		streamId, err := r.publisher.PublishEvent(ctx, r.cfg.Stream, r.channel, r.cfg.StreamMaxLen, event.Event)
+		if err != nil {
+			retryAt := time.Now().Add(r.backoff(event.Attempts))
+			r.logger.Printf("Error publishing outbox event %d (attempt %d), retrying at %s: %s\n", event.Id, event.Attempts, retryAt.Format(time.RFC3339), err)
+			if err := r.outbox.MarkOutboxFailed(ctx, &models.MarkOutboxFailedRequest{
+				Id:      event.Id,
+				Error:   err.Error(),
+				RetryAt: retryAt,
+			}); err != nil {
+				r.logger.Println("Error recording outbox failure:", err)
+			}
+			continue
+		}
+
+		// If this fails the lease runs out and the event is published again.
+		if err := r.outbox.MarkOutboxPublished(ctx, &models.MarkOutboxPublishedRequest{
+			Id:       event.Id,
+			StreamId: streamId,
+		}); err != nil {
+			r.logger.Println("Error marking outbox event as published:", err)
+		}
+	}
+	return len(events)
+}
+
+// sweep deletes the events published more than Retention ago, a batch at a
+// time so no delete holds its locks for long.
+func (r *Relay) sweep(ctx context.Context) {
+	r.swept = time.Now()
+	req := &models.PurgePublishedOutboxEventsRequest{
+		PublishedBefore: r.swept.Add(-r.cfg.Retention),
+		Limit:           sweepBatch,
+	}
+	var purged int64
+	for ctx.Err() == nil {
+		response, err := r.outbox.PurgePublishedOutboxEvents(ctx, req)
+		if err != nil {
+			r.logger.Println("Error sweeping the outbox:", err)
+			break
+		}
+		purged += response.Purged
+		if response.Purged < sweepBatch {
+			break
+		}
+	}
+	if purged > 0 {
+		r.logger.Printf("Swept %d outbox events published more than %s ago\n", purged, r.cfg.Retention)
+	}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := baseBackoff
+	for i := 1; i < attempts && backoff < r.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.cfg.MaxBackoff {
+		backoff = r.cfg.MaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,164 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// fakeOutbox hands out pending events and records how they ended.
+	fakeOutbox struct {
+		repository.IOutboxRepo
+		pending   []*models.OutboxEvent
+		published []*models.MarkOutboxPublishedRequest
+		failed    []*models.MarkOutboxFailedRequest
+		// expired is how many published events are past the retention
+		// window; sweeps is every purge request.
+		expired int64
+		sweeps  []*models.PurgePublishedOutboxEventsRequest
+	}
+
+	// fakePublisher fails the events in fail and publishes the others.
+	fakePublisher struct {
+		fail      map[string]bool
+		published []*models.BookEvent
+	}
+)
+
+func (f *fakeOutbox) ClaimOutboxEvents(_ context.Context, req *models.ClaimOutboxEventsRequest) ([]*models.OutboxEvent, error) {
+	claimed := f.pending
+	if len(claimed) > req.Limit {
+		claimed = claimed[:req.Limit]
+	}
+	f.pending = f.pending[len(claimed):]
+	return claimed, nil
+}
+
+func (f *fakeOutbox) MarkOutboxPublished(_ context.Context, req *models.MarkOutboxPublishedRequest) error {
+	f.published = append(f.published, req)
+	return nil
+}
+
+func (f *fakeOutbox) MarkOutboxFailed(_ context.Context, req *models.MarkOutboxFailedRequest) error {
+	f.failed = append(f.failed, req)
+	return nil
+}
+
+func (f *fakeOutbox) PurgePublishedOutboxEvents(_ context.Context, req *models.PurgePublishedOutboxEventsRequest) (*models.PurgePublishedOutboxEventsResponse, error) {
+	f.sweeps = append(f.sweeps, req)
+	purged := min(f.expired, int64(req.Limit))
+	f.expired -= purged
+	return &models.PurgePublishedOutboxEventsResponse{Purged: purged}, nil
+}
+
+func (p *fakePublisher) PublishEvent(_ context.Context, stream, channel string, _ int64, event *models.BookEvent) (string, error) {
+	if p.fail[event.EventId] {
+		return "", errors.New("redis: connection refused")
+	}
+	p.published = append(p.published, event)
+	return stream + "-" + event.EventId, nil
+}
+
+func testConfig() config.OutboxConfig {
+	return config.OutboxConfig{
+		Stream:        "books:events",
+		BatchSize:     10,
+		Lease:         time.Minute,
+		MaxBackoff:    time.Minute,
+		Retention:     time.Hour,
+		SweepInterval: time.Hour,
+	}
+}
+
+func outboxEvent(id int64, attempts int, eventId string) *models.OutboxEvent {
+	return &models.OutboxEvent{Id: id, Attempts: attempts, Event: &models.BookEvent{EventId: eventId, Type: models.EventBookCreated}}
+}
+
+func TestRelayBatch(t *testing.T) {
+	outbox := &fakeOutbox{pending: []*models.OutboxEvent{
+		outboxEvent(1, 1, "e1"),
+		outboxEvent(2, 3, "e2"),
+		outboxEvent(3, 1, "e3"),
+	}}
+	publisher := &fakePublisher{fail: map[string]bool{"e2": true}}
+	relay := New(outbox, publisher, testConfig(), "books:events:live", log.New(io.Discard, "", 0))
+
+	start := time.Now()
+	if claimed := relay.relayBatch(context.Background()); claimed != 3 {
+		t.Fatalf("claimed %d events, want 3", claimed)
+	}
+
+	if len(outbox.published) != 2 || outbox.published[0].Id != 1 || outbox.published[1].Id != 3 {
+		t.Fatalf("marked published %+v, want events 1 and 3", outbox.published)
+	}
+	if outbox.published[0].StreamId != "books:events-e1" {
+		t.Errorf("recorded stream id %q", outbox.published[0].StreamId)
+	}
+	if len(outbox.failed) != 1 || outbox.failed[0].Id != 2 || outbox.failed[0].Error == "" {
+		t.Fatalf("marked failed %+v, want event 2 with its error", outbox.failed)
+	}
+	// The third attempt waits four times the first backoff.
+	if retryIn := outbox.failed[0].RetryAt.Sub(start); retryIn < 4*time.Second || retryIn > 5*time.Second {
+		t.Errorf("retrying in %s, want 4s", retryIn)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	relay := New(nil, nil, testConfig(), "", log.New(io.Discard, "", 0))
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{6, 32 * time.Second},
+		{7, time.Minute},
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := relay.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff after %d attempts is %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	outbox := &fakeOutbox{expired: 2*sweepBatch + 1}
+	relay := New(outbox, &fakePublisher{}, testConfig(), "", log.New(io.Discard, "", 0))
+
+	relay.sweep(context.Background())
+	if outbox.expired != 0 {
+		t.Fatalf("%d expired events left", outbox.expired)
+	}
+	if len(outbox.sweeps) != 3 {
+		t.Fatalf("swept in %d batches, want 3", len(outbox.sweeps))
+	}
+	if before := time.Since(outbox.sweeps[0].PublishedBefore); before < time.Hour || before > time.Hour+time.Minute {
+		t.Errorf("swept events published before %s ago, want an hour", before)
+	}
+}
+
+// TestRunSweeps makes sure the relay sweeps when it starts and then only
+// every SweepInterval.
+func TestRunSweeps(t *testing.T) {
+	outbox := &fakeOutbox{}
+	cfg := testConfig()
+	cfg.PollInterval = time.Millisecond
+	relay := New(outbox, &fakePublisher{}, cfg, "", log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	relay.Run(ctx)
+	if len(outbox.sweeps) != 1 {
+		t.Fatalf("swept %d times, want once", len(outbox.sweeps))
+	}
+}
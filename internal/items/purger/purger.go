@@ -0,0 +1,57 @@
+package purger
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// Purger permanently removes books that have been in the trash for longer
+	// than the retention window.
+	Purger struct {
+		repo   repository.IBookRepo
+		cfg    config.TrashConfig
+		logger *log.Logger
+	}
+)
+
+func New(repo repository.IBookRepo, cfg config.TrashConfig, logger *log.Logger) *Purger {
+	return &Purger{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run purges once immediately and then on every interval until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	response, err := p.repo.PurgeDeletedBooks(ctx, &models.PurgeDeletedBooksRequest{
+		DeletedBefore: time.Now().Add(-p.cfg.Retention),
+	})
+	if err != nil {
+		p.logger.Println("Error purging deleted books:", err)
+		return
+	}
+	if response.Purged > 0 {
+		p.logger.Printf("Purged %d books deleted more than %s ago\n", response.Purged, p.cfg.Retention)
+	}
+}
@@ -0,0 +1,169 @@
+package redisservice
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Breaker states, as reported under "redis_breaker" in /debug/vars.
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+// ErrBreakerOpen is what a Redis command fails with, without being sent,
+// while the breaker is open. Callers treat it like any other Redis failure,
+// which for the cache means going to Postgres.
+var ErrBreakerOpen = errors.New("redis: circuit breaker is open")
+
+var (
+	breakerStats = expvar.NewMap("redis_breaker")
+	breakerState = new(expvar.String)
+)
+
+func init() {
+	breakerState.Set(breakerClosed)
+	breakerStats.Set("state", breakerState)
+}
+
+type (
+	// breaker is a circuit breaker hooked into the Redis client. After
+	// threshold commands in a row fail to reach Redis it opens, and commands
+	// fail fast with ErrBreakerOpen for cooldown. Then a single command is let
+	// through as a trial: if it reaches Redis the breaker closes, otherwise
+	// it opens again. Replies such as redis.Nil or a script error mean Redis
+	// is up and are not failures. onOpen is called every time it opens.
+	breaker struct {
+		threshold int
+		cooldown  time.Duration
+		logger    *log.Logger
+		onOpen    func()
+
+		mu       sync.Mutex
+		state    string
+		failures int
+		openedAt time.Time
+		// trial is set while the half-open breaker waits on its trial
+		// command.
+		trial bool
+	}
+)
+
+func newBreaker(threshold int, cooldown time.Duration, logger *log.Logger, onOpen func()) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, logger: logger, onOpen: onOpen, state: breakerClosed}
+}
+
+// allow reports whether a command may be sent now.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			breakerStats.Add("rejected", 1)
+			return false
+		}
+		b.setState(breakerHalfOpen)
+		b.trial = true
+		return true
+	case breakerHalfOpen:
+		if b.trial {
+			breakerStats.Add("rejected", 1)
+			return false
+		}
+		b.trial = true
+		return true
+	}
+	return true
+}
+
+// done records how a command that was sent went.
+func (b *breaker) done(err error) {
+	if errors.Is(err, context.Canceled) {
+		// The caller gave up, which says nothing about Redis.
+		b.mu.Lock()
+		b.trial = false
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if !unreachable(err) {
+		b.failures = 0
+		b.setState(breakerClosed)
+		return
+	}
+	breakerStats.Add("failures", 1)
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		if b.state != breakerOpen {
+			breakerStats.Add("opens", 1)
+			b.onOpen()
+		}
+		b.setState(breakerOpen)
+	}
+}
+
+func (b *breaker) setState(state string) {
+	if state == b.state {
+		return
+	}
+	b.logger.Printf("REDIS CIRCUIT BREAKER %s\n", state)
+	b.state = state
+	breakerState.Set(state)
+}
+
+// unreachable tells a failure to talk to Redis from a reply of Redis.
+func unreachable(err error) bool {
+	if err == nil || err == redis.Nil {
+		return false
+	}
+	var reply redis.Error
+	return !errors.As(err, &reply)
+}
+
+func (b *breaker) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	if !b.allow() {
+		return ctx, ErrBreakerOpen
+	}
+	return ctx, nil
+}
+
+func (b *breaker) AfterProcess(_ context.Context, cmd redis.Cmder) error {
+	if err := cmd.Err(); err != ErrBreakerOpen {
+		b.done(err)
+	}
+	return nil
+}
+
+func (b *breaker) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	if !b.allow() {
+		return ctx, ErrBreakerOpen
+	}
+	return ctx, nil
+}
+
+func (b *breaker) AfterProcessPipeline(_ context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if err = cmd.Err(); err == ErrBreakerOpen {
+			return nil
+		} else if unreachable(err) {
+			break
+		}
+	}
+	b.done(err)
+	return nil
+}
@@ -0,0 +1,162 @@
+package redisservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+var errUnreachable = errors.New("dial tcp: connection refused")
+
+// replyError is an error reply of Redis.
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+
+func (replyError) RedisError() {}
+
+func TestBreaker(t *testing.T) {
+	opens := 0
+	b := newBreaker(3, 20*time.Millisecond, log.New(io.Discard, "", 0), func() { opens++ })
+	send := func(err error) bool {
+		t.Helper()
+		if !b.allow() {
+			return false
+		}
+		b.done(err)
+		return true
+	}
+	expect := func(state string) {
+		t.Helper()
+		if b.state != state {
+			t.Fatalf("breaker is %s, want %s", b.state, state)
+		}
+	}
+
+	// Replies of Redis, and callers giving up, are not failures.
+	for _, err := range []error{errUnreachable, errUnreachable, redis.Nil, errUnreachable, errUnreachable, replyError("ERR script"), context.Canceled} {
+		send(err)
+	}
+	expect(breakerClosed)
+
+	for i := 0; i < 3; i++ {
+		send(errUnreachable)
+	}
+	expect(breakerOpen)
+	if opens != 1 {
+		t.Fatalf("onOpen called %d times, want 1", opens)
+	}
+	if send(nil) {
+		t.Fatal("an open breaker let a command through")
+	}
+
+	// After the cooldown a single trial goes through; a failed one opens the
+	// breaker again for another cooldown.
+	time.Sleep(25 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("no trial after the cooldown")
+	}
+	expect(breakerHalfOpen)
+	if b.allow() {
+		t.Fatal("a second command went through during the trial")
+	}
+	b.done(errUnreachable)
+	expect(breakerOpen)
+	if send(nil) {
+		t.Fatal("the breaker let a command through right after a failed trial")
+	}
+	if opens != 2 {
+		t.Fatalf("onOpen called %d times, want 2", opens)
+	}
+
+	// A trial the caller gave up on lets the next command try.
+	time.Sleep(25 * time.Millisecond)
+	b.allow()
+	b.done(context.Canceled)
+	expect(breakerHalfOpen)
+	if !send(redis.Nil) {
+		t.Fatal("no trial after one was canceled")
+	}
+	expect(breakerClosed)
+	if b.failures != 0 {
+		t.Errorf("%d failures left after closing", b.failures)
+	}
+}
+
+func TestFlushAfterOutage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mr := miniredis.RunT(t)
+	cfg := testConfig()
+	cfg.BreakerThreshold = 1
+	cfg.BreakerCooldown = 10 * time.Millisecond
+	r, other := testService(t, mr, cfg), testService(t, mr, testConfig())
+	go other.ListenInvalidations(ctx)
+	eventually(t, "the subscription", func() bool {
+		return mr.PubSubNumSub("test:" + invalidationChannel)["test:"+invalidationChannel] == 1
+	})
+
+	book := &models.Book{BookId: "b1", Version: 1}
+	for _, service := range []*RedisService{r, other} {
+		if err := service.CacheBook(ctx, book); err != nil {
+			t.Fatalf("CacheBook: %v", err)
+		}
+	}
+	epoch, _ := r.QueryEpoch(ctx)
+	if err := r.StoreQuery(ctx, "all", "h1", epoch, []string{"b1"}, []string{"book:b1"}); err != nil {
+		t.Fatalf("StoreQuery: %v", err)
+	}
+	if err := r.SetCatalogModified(ctx, time.Now()); err != nil {
+		t.Fatalf("SetCatalogModified: %v", err)
+	}
+
+	// The book changes while r cannot reach Redis, so its invalidation is
+	// lost and Redis, and the other replica, keep version 1.
+	mr.Close()
+	if err := r.InvalidateBook(ctx, "b1", 2); err == nil {
+		t.Fatal("InvalidateBook reached a stopped Redis")
+	}
+	if got, _, _ := r.GetBook(ctx, "b1"); got != nil {
+		t.Fatal("the cache was read during the outage")
+	}
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if got, _, err := r.GetBook(ctx, "b1"); got != nil || err != nil {
+		t.Fatalf("got %+v, %v after the outage; want a miss", got, err)
+	}
+	var result []string
+	if hit, _ := r.GetQuery(ctx, "all", "h1", &result); hit {
+		t.Error("a query cached before the outage hit")
+	}
+	if modifiedAt, _ := r.GetCatalogModified(ctx); !modifiedAt.IsZero() {
+		t.Error("the catalog version from before the outage is still cached")
+	}
+	// A query read under the epoch from before the outage is not stored.
+	if err := r.StoreQuery(ctx, "all", "h1", epoch, []string{"b1"}, []string{"book:b1"}); err != nil {
+		t.Fatalf("StoreQuery: %v", err)
+	}
+	if hit, _ := r.GetQuery(ctx, "all", "h1", &result); hit {
+		t.Error("a query read before the flush was stored after it")
+	}
+	eventually(t, "the other replica to drop the book", func() bool {
+		return other.local.get("b1") == nil
+	})
+
+	// Once flushed, the cache is used again.
+	if err := r.CacheBook(ctx, &models.Book{BookId: "b1", Version: 2}); err != nil {
+		t.Fatalf("CacheBook: %v", err)
+	}
+	if got, _, _ := r.GetBook(ctx, "b1"); got == nil || got.Version != 2 {
+		t.Fatalf("got %+v, want version 2", got)
+	}
+}
@@ -0,0 +1,97 @@
+package redisservice
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type (
+	// localCache is the in-process tier in front of Redis: a bounded LRU of
+	// book entries, each kept for at most ttl. It follows the rules of the
+	// Redis tier, so a tombstone fences off older versions here too. A nil
+	// localCache is a disabled tier, which never hits.
+	localCache struct {
+		mu      sync.Mutex
+		size    int
+		ttl     time.Duration
+		order   *list.List
+		entries map[string]*list.Element
+	}
+
+	localEntry struct {
+		bookId  string
+		entry   *bookEntry
+		expires time.Time
+	}
+)
+
+func newLocalCache(size int, ttl time.Duration) *localCache {
+	return &localCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// get returns the entry cached for bookId, which may be a tombstone, or nil.
+func (l *localCache) get(bookId string) *bookEntry {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[bookId]
+	if !ok {
+		return nil
+	}
+	cached := element.Value.(*localEntry)
+	if time.Now().After(cached.expires) {
+		l.order.Remove(element)
+		delete(l.entries, bookId)
+		return nil
+	}
+	l.order.MoveToFront(element)
+	return cached.entry
+}
+
+// store caches entry unless a later version of the book is cached already,
+// evicting the least recently used book when the tier is full.
+func (l *localCache) store(bookId string, entry *bookEntry) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := time.Now().Add(l.ttl)
+	if element, ok := l.entries[bookId]; ok {
+		cached := element.Value.(*localEntry)
+		if cached.entry.Version > entry.Version && time.Now().Before(cached.expires) {
+			return
+		}
+		cached.entry, cached.expires = entry, expires
+		l.order.MoveToFront(element)
+		return
+	}
+	l.entries[bookId] = l.order.PushFront(&localEntry{bookId: bookId, entry: entry, expires: expires})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*localEntry).bookId)
+	}
+}
+
+// clear drops every entry, for when invalidations may have been missed.
+func (l *localCache) clear() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	l.entries = map[string]*list.Element{}
+}
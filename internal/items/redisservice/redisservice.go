@@ -3,68 +3,565 @@ package redisservice
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/boock/internal/items/config"
 	"github.com/ruziba3vich/boock/internal/models"
 )
 
+const (
+	catalogModifiedKey = "catalog:modified"
+	// persistedQueryPrefix is followed by the sha256 of the query.
+	persistedQueryPrefix = "graphql:pq:"
+	bookPrefix           = "book:"
+	// lockPrefix is followed by the id of the book being refilled.
+	lockPrefix = "lock:book:"
+	// queryPrefix is followed by the query type and the hash of the query.
+	queryPrefix = "query:"
+	tagPrefix   = "tag:"
+	// queryEpochKey is bumped by every invalidation of cached queries.
+	queryEpochKey = "query:epoch"
+	// invalidationChannel tells every replica which books to drop from its
+	// in-process tier.
+	invalidationChannel = "cache:invalidate"
+	// flushBatch is how many keys a flush scans for and deletes at a time.
+	flushBatch = 1000
+)
+
+// storeIfNewer writes an entry unless the one already cached is of a later
+// version. It keeps a request that read a book before a change from caching
+// it after the change was invalidated.
+var storeIfNewer = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	local ok, entry = pcall(cjson.decode, current)
+	if ok and type(entry) == 'table' and tonumber(entry.version) and tonumber(entry.version) > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+return 1
+`)
+
+// storeIfLater raises a time in Unix nanoseconds. The values are compared as
+// strings, since Lua numbers are doubles and would round them.
+var storeIfLater = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and (#current > #ARGV[1] or (#current == #ARGV[1] and current >= ARGV[1])) then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1])
+return 1
+`)
+
+// releaseLock deletes a lock only if it is still held with the token it was
+// taken with, so a holder that overran LockTTL cannot release another's.
+var releaseLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// storeQuery caches a query result and adds it to its tags, unless the
+// query epoch moved on since the result was read, which means a change that
+// may be missing from it was invalidated in the meantime.
+var storeQuery = redis.NewScript(`
+if (redis.call('GET', KEYS[1]) or '0') ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+for i = 3, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[2])
+	redis.call('PEXPIRE', KEYS[i], ARGV[3])
+end
+return 1
+`)
+
+// invalidateTags bumps the query epoch and drops every query under the tags.
+var invalidateTags = redis.NewScript(`
+redis.call('INCR', KEYS[1])
+for i = 2, #KEYS do
+	for _, key in ipairs(redis.call('SMEMBERS', KEYS[i])) do
+		redis.call('DEL', key)
+	end
+	redis.call('DEL', KEYS[i])
+end
+return 1
+`)
+
 type (
+	// RedisService caches books in two tiers: an in-process one in front of
+	// the one in Redis, which all replicas share. Either tier can be
+	// disabled. Invalidations always reach both, so a replica with a tier
+	// disabled does not leave the others stale.
+	//
+	// While the breaker is open, invalidations are lost, so once Redis is
+	// back both tiers may serve what changed during the outage. outages
+	// counts the times the breaker opened, and flushed the outages the cache
+	// was flushed after; until they match, the cache is bypassed.
 	RedisService struct {
 		redisDb *redis.Client
+		cfg     config.RedisConfig
+		local   *localCache
 		logger  *log.Logger
+
+		outages  atomic.Int64
+		flushed  atomic.Int64
+		flushing sync.Mutex
+	}
+
+	// bookEntry is what a book is cached as. An entry without a book is a
+	// tombstone: it reads as a miss, but still fences off older versions.
+	// After FreshUntil, in Unix milliseconds, the book is stale.
+	bookEntry struct {
+		Version    int64        `json:"version"`
+		Book       *models.Book `json:"book,omitempty"`
+		FreshUntil int64        `json:"fresh_until,omitempty"`
+	}
+
+	// invalidation is broadcast on invalidationChannel when a book changes.
+	// One without a book id drops every book, after a flush.
+	invalidation struct {
+		BookId  string `json:"book_id"`
+		Version int64  `json:"version"`
 	}
 )
 
-func New(redisDb *redis.Client, logger *log.Logger) *RedisService {
-	return &RedisService{
+// New puts a circuit breaker in front of redisDb, so that while Redis is
+// down every call fails fast and the cache is bypassed.
+func New(redisDb *redis.Client, cfg config.RedisConfig, logger *log.Logger) *RedisService {
+	var local *localCache
+	if !cfg.DisableLocal {
+		local = newLocalCache(cfg.LocalSize, cfg.LocalTTL)
+	}
+	r := &RedisService{
 		logger:  logger,
+		cfg:     cfg,
+		local:   local,
 		redisDb: redisDb,
 	}
+	redisDb.AddHook(newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown, logger, func() { r.outages.Add(1) }))
+	return r
+}
+
+// ready reports whether the cache may be used. After an outage it may not,
+// until a call that finds Redis back has flushed it; the call doing so waits
+// for the flush, the ones meanwhile bypass the cache.
+func (r *RedisService) ready(ctx context.Context) bool {
+	if r.outages.Load() == r.flushed.Load() {
+		return true
+	}
+	if !r.flushing.TryLock() {
+		return false
+	}
+	defer r.flushing.Unlock()
+
+	outages := r.outages.Load()
+	if outages == r.flushed.Load() {
+		return true
+	}
+	if err := r.flush(context.WithoutCancel(ctx)); err != nil {
+		if !errors.Is(err, ErrBreakerOpen) {
+			r.logger.Printf("ERROR WHILE FLUSHING THE CACHE : %s\n", err.Error())
+		}
+		return false
+	}
+	r.flushed.Store(outages)
+	r.logger.Println("CACHE FLUSHED AFTER THE REDIS OUTAGE")
+	return true
 }
 
-func (r *RedisService) StoreBookInRedis(ctx context.Context, book *models.Book) (*models.Book, error) {
-	byteData, err := json.Marshal(book)
+// flush drops every cached book and query here, in Redis and on every other
+// replica, and the catalog version. The query epoch is bumped first, so a
+// query read before the flush is not stored after it.
+func (r *RedisService) flush(ctx context.Context) error {
+	r.local.clear()
+	if err := r.redisDb.Incr(ctx, r.key(queryEpochKey)).Err(); err != nil {
+		return err
+	}
+	for _, prefix := range []string{bookPrefix, queryPrefix, tagPrefix} {
+		iter := r.redisDb.Scan(ctx, 0, r.key(prefix)+"*", flushBatch).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			if iter.Val() != r.key(queryEpochKey) {
+				keys = append(keys, iter.Val())
+			}
+			if len(keys) == flushBatch {
+				if err := r.redisDb.Unlink(ctx, keys...).Err(); err != nil {
+					return err
+				}
+				keys = keys[:0]
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := r.redisDb.Unlink(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.redisDb.Del(ctx, r.key(catalogModifiedKey)).Err(); err != nil {
+		return err
+	}
+	message, err := json.Marshal(&invalidation{})
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return r.redisDb.Publish(ctx, r.key(invalidationChannel), message).Err()
+}
 
-	if err := r.redisDb.Set(ctx, book.BookId, byteData, time.Hour*24).Err(); err != nil {
-		return nil, err
+// key puts name in the namespace of the service, so several deployments can
+// share one Redis database.
+func (r *RedisService) key(name string) string {
+	return r.cfg.KeyPrefix + ":" + name
+}
+
+func (e *bookEntry) fresh() bool {
+	return time.Now().UnixMilli() < e.FreshUntil
+}
+
+// book is a copy of the cached book, which callers are free to change.
+func (e *bookEntry) book() *models.Book {
+	book := *e.Book
+	return &book
+}
+
+// CacheBook stores book unless a later version of it is cached already. It
+// is fresh for BookTTL, then served stale for StaleGrace.
+func (r *RedisService) CacheBook(ctx context.Context, book *models.Book) error {
+	if !r.ready(ctx) {
+		return nil
+	}
+	copied := *book
+	entry := &bookEntry{
+		Version:    book.Version,
+		Book:       &copied,
+		FreshUntil: time.Now().Add(r.cfg.BookTTL).UnixMilli(),
 	}
-	return book, nil
+	r.local.store(book.BookId, entry)
+	if r.cfg.DisableShared {
+		return nil
+	}
+	return r.storeBookEntry(ctx, book.BookId, entry)
 }
 
-func (r *RedisService) GetBookFromRedis(ctx context.Context, bookId string) (*models.Book, error) {
-	redisBook, err := r.redisDb.Get(ctx, bookId).Result()
+// InvalidateBook drops the cached copy of a book that changed to version,
+// here, in Redis and, through invalidationChannel, on every other replica.
+// It leaves a tombstone rather than deleting the key, so a copy older than
+// version cannot be cached again until the tombstone expires.
+func (r *RedisService) InvalidateBook(ctx context.Context, bookId string, version int64) error {
+	r.local.store(bookId, &bookEntry{Version: version})
+	if err := r.storeBookEntry(ctx, bookId, &bookEntry{Version: version}); err != nil {
+		return err
+	}
+	message, err := json.Marshal(&invalidation{BookId: bookId, Version: version})
+	if err != nil {
+		return err
+	}
+	return r.redisDb.Publish(ctx, r.key(invalidationChannel), message).Err()
+}
+
+func (r *RedisService) storeBookEntry(ctx context.Context, bookId string, entry *bookEntry) error {
+	byteData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return storeIfNewer.Run(ctx, r.redisDb, []string{r.key(bookPrefix + bookId)}, byteData, entry.Version, (r.cfg.BookTTL + r.cfg.StaleGrace).Milliseconds()).Err()
+}
+
+// GetBook returns the cached book, or nil when it is not cached. stale
+// reports a book past BookTTL, which should be refreshed. A stale book in
+// the in-process tier is only served when Redis has nothing better. A book
+// found in Redis is kept in the in-process tier for the next read.
+func (r *RedisService) GetBook(ctx context.Context, bookId string) (book *models.Book, stale bool, err error) {
+	if !r.ready(ctx) {
+		return nil, false, nil
+	}
+	local := r.local.get(bookId)
+	if local != nil && local.Book == nil {
+		local = nil
+	}
+	if local != nil && local.fresh() {
+		return local.book(), false, nil
+	}
+	if r.cfg.DisableShared {
+		if local != nil {
+			return local.book(), true, nil
+		}
+		return nil, false, nil
+	}
+
+	redisBook, err := r.redisDb.Get(ctx, r.key(bookPrefix+bookId)).Result()
 	if err == redis.Nil {
-		return nil, nil
+		return nil, false, nil
 	} else if err != nil {
 		r.logger.Printf("ERROR WHILE GETTING DATA FROM REDIS : %s\n", err.Error())
-		return nil, err
+		if local != nil {
+			return local.book(), true, nil
+		}
+		return nil, false, err
 	}
 
-	var book models.Book
-	err = json.Unmarshal([]byte(redisBook), &book)
+	var entry bookEntry
+	err = json.Unmarshal([]byte(redisBook), &entry)
 	if err != nil {
 		r.logger.Printf("ERROR WHILE MARSHALING DATA : %s\n", err.Error())
-		return nil, err
+		return nil, false, err
+	}
+	r.local.store(bookId, &entry)
+	if entry.Book == nil {
+		return nil, false, nil
+	}
+	return entry.book(), !entry.fresh(), nil
+}
+
+// LockBook takes the lock on refilling the cached copy of a book, for at
+// most LockTTL, and returns the function that releases it. It reports false
+// when another process holds the lock. With the Redis tier disabled there is
+// nothing to share a refill with, so the lock is always taken.
+func (r *RedisService) LockBook(ctx context.Context, bookId string) (func(), bool, error) {
+	if r.cfg.DisableShared {
+		return func() {}, true, nil
+	}
+	key := r.key(lockPrefix + bookId)
+	token := uuid.NewString()
+	locked, err := r.redisDb.SetNX(ctx, key, token, r.cfg.LockTTL).Result()
+	if err != nil || !locked {
+		return nil, false, err
+	}
+	return func() {
+		if err := releaseLock.Run(ctx, r.redisDb, []string{key}, token).Err(); err != nil {
+			r.logger.Printf("ERROR WHILE RELEASING LOCK %s : %s\n", key, err.Error())
+		}
+	}, true, nil
+}
+
+// ListenInvalidations applies the invalidations other replicas broadcast to
+// the in-process tier until ctx is done. Pub/Sub drops messages while the
+// subscription is down, so the tier is cleared every time it is made again.
+func (r *RedisService) ListenInvalidations(ctx context.Context) {
+	if r.local == nil {
+		return
+	}
+	pubsub := r.redisDb.Subscribe(ctx, r.key(invalidationChannel))
+	go func() {
+		<-ctx.Done()
+		pubsub.Close()
+	}()
+
+	for {
+		message, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			r.logger.Printf("ERROR WHILE RECEIVING CACHE INVALIDATIONS : %s\n", err.Error())
+			r.local.clear()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch message := message.(type) {
+		case *redis.Subscription:
+			r.local.clear()
+		case *redis.Message:
+			var inv invalidation
+			if err := json.Unmarshal([]byte(message.Payload), &inv); err != nil {
+				r.logger.Printf("ERROR WHILE UNMARSHALING CACHE INVALIDATION : %s\n", err.Error())
+				continue
+			}
+			if inv.BookId == "" {
+				r.local.clear()
+				continue
+			}
+			r.local.store(inv.BookId, &bookEntry{Version: inv.Version})
+		}
+	}
+}
+
+// QueryEpoch is read before running a query whose result is to be cached,
+// and handed back to StoreQuery.
+func (r *RedisService) QueryEpoch(ctx context.Context) (string, error) {
+	epoch, err := r.redisDb.Get(ctx, r.key(queryEpochKey)).Result()
+	if err == redis.Nil {
+		return "0", nil
+	}
+	return epoch, err
+}
+
+// GetQuery reads the cached result of a query into result and reports
+// whether there was one.
+func (r *RedisService) GetQuery(ctx context.Context, queryType, hash string, result interface{}) (bool, error) {
+	if r.cfg.DisableShared || !r.ready(ctx) {
+		return false, nil
 	}
-	return &book, nil
+	data, err := r.redisDb.Get(ctx, r.key(queryPrefix+queryType+":"+hash)).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func (r *RedisService) DeleteBookFromRedis(ctx context.Context, bookId string) error {
-	result, err := r.redisDb.Del(ctx, bookId).Result()
+// StoreQuery caches the result of a query under tags, if the query epoch is
+// still the one read before the query ran.
+func (r *RedisService) StoreQuery(ctx context.Context, queryType, hash, epoch string, result interface{}, tags []string) error {
+	if r.cfg.DisableShared || !r.ready(ctx) {
+		return nil
+	}
+	data, err := json.Marshal(result)
 	if err != nil {
 		return err
 	}
+	keys := []string{r.key(queryEpochKey), r.key(queryPrefix + queryType + ":" + hash)}
+	for _, tag := range tags {
+		keys = append(keys, r.key(tagPrefix+tag))
+	}
+	return storeQuery.Run(ctx, r.redisDb, keys, epoch, data, r.cfg.QueryTTL.Milliseconds()).Err()
+}
 
-	if result == 0 {
-		r.logger.Printf("User with ID %s does not exist in Redis", bookId)
-	} else {
-		r.logger.Printf("User with ID %s has been deleted from Redis", bookId)
+// InvalidateQueries drops the cached queries under any of tags.
+func (r *RedisService) InvalidateQueries(ctx context.Context, tags []string) error {
+	keys := []string{r.key(queryEpochKey)}
+	for _, tag := range tags {
+		keys = append(keys, r.key(tagPrefix+tag))
 	}
+	return invalidateTags.Run(ctx, r.redisDb, keys).Err()
+}
+
+// GetCatalogModified returns the last catalog change time, or the zero time
+// when it is not cached.
+func (r *RedisService) GetCatalogModified(ctx context.Context) (time.Time, error) {
+	if !r.ready(ctx) {
+		return time.Time{}, nil
+	}
+	nanos, err := r.redisDb.Get(ctx, r.key(catalogModifiedKey)).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nanos).UTC(), nil
+}
+
+// SetCatalogModified stores the last catalog change time, unless a later one
+// is stored already.
+func (r *RedisService) SetCatalogModified(ctx context.Context, modifiedAt time.Time) error {
+	return storeIfLater.Run(ctx, r.redisDb, []string{r.key(catalogModifiedKey)}, modifiedAt.UnixNano()).Err()
+}
+
+// GetPersistedQuery returns the GraphQL query stored under hash, or an empty
+// string when there is none.
+func (r *RedisService) GetPersistedQuery(ctx context.Context, hash string) (string, error) {
+	query, err := r.redisDb.Get(ctx, r.key(persistedQueryPrefix+hash)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return query, err
+}
+
+func (r *RedisService) StorePersistedQuery(ctx context.Context, hash, query string, ttl time.Duration) error {
+	return r.redisDb.Set(ctx, r.key(persistedQueryPrefix+hash), query, ttl).Err()
+}
+
+// PublishEvent appends event to stream, trimming the stream to roughly maxLen
+// entries, and announces it on channel for live listeners. Both names are
+// put in the namespace of the service, as are those of the other event
+// methods. It returns the ID Redis gave the entry. The stream is the source
+// of truth: a failed announce is only logged, since listeners can catch up
+// from the stream.
+func (r *RedisService) PublishEvent(ctx context.Context, stream, channel string, maxLen int64, event *models.BookEvent) (string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	id, err := r.redisDb.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.key(stream),
+		MaxLen: maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"event_id": event.EventId,
+			"type":     event.Type,
+			"book_id":  event.BookId,
+			"payload":  payload,
+		},
+	}).Result()
+	if err != nil {
+		return "", err
+	}
+
+	message, err := json.Marshal(&models.StreamedBookEvent{Id: id, Event: event})
+	if err != nil {
+		return id, err
+	}
+	if err := r.redisDb.Publish(ctx, r.key(channel), message).Err(); err != nil {
+		r.logger.Printf("ERROR WHILE ANNOUNCING EVENT %s : %s\n", id, err.Error())
+	}
+	return id, nil
+}
+
+// StreamBounds returns the IDs of the oldest and the newest entry left in
+// stream, or empty strings when it has none. It reads them with XRANGE
+// rather than XINFO STREAM, whose Redis 7 reply the client cannot parse.
+func (r *RedisService) StreamBounds(ctx context.Context, stream string) (string, string, error) {
+	first, err := r.redisDb.XRangeN(ctx, r.key(stream), "-", "+", 1).Result()
+	if err != nil || len(first) == 0 {
+		return "", "", err
+	}
+	last, err := r.redisDb.XRevRangeN(ctx, r.key(stream), "+", "-", 1).Result()
+	if err != nil || len(last) == 0 {
+		return "", "", err
+	}
+	return first[0].ID, last[0].ID, nil
+}
+
+// ReadEventsAfter returns up to count events of stream that come after the
+// entry afterId, oldest first.
+func (r *RedisService) ReadEventsAfter(ctx context.Context, stream, afterId string, count int64) ([]*models.StreamedBookEvent, error) {
+	// XRANGE is inclusive, so ask for one more and drop afterId itself.
+	messages, err := r.redisDb.XRangeN(ctx, r.key(stream), afterId, "+", count+1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.StreamedBookEvent, 0, len(messages))
+	for _, message := range messages {
+		if message.ID == afterId {
+			continue
+		}
+		payload, _ := message.Values["payload"].(string)
+		var event models.BookEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			r.logger.Printf("ERROR WHILE UNMARSHALING STREAM ENTRY %s : %s\n", message.ID, err.Error())
+			continue
+		}
+		events = append(events, &models.StreamedBookEvent{Id: message.ID, Event: &event})
+	}
+	if int64(len(events)) > count {
+		events = events[:count]
+	}
+	return events, nil
+}
 
-	return nil
+// SubscribeEvents listens on channel for events announced by PublishEvent.
+// The caller must close the subscription.
+func (r *RedisService) SubscribeEvents(ctx context.Context, channel string) *redis.PubSub {
+	return r.redisDb.Subscribe(ctx, r.key(channel))
 }
@@ -0,0 +1,227 @@
+package redisservice
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func testConfig() config.RedisConfig {
+	return config.RedisConfig{
+		KeyPrefix:        "test",
+		BookTTL:          time.Hour,
+		StaleGrace:       time.Minute,
+		LockTTL:          time.Second,
+		QueryTTL:         time.Minute,
+		LocalSize:        100,
+		LocalTTL:         time.Minute,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Second,
+	}
+}
+
+func testService(t *testing.T, mr *miniredis.Miniredis, cfg config.RedisConfig) *RedisService {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return New(client, cfg, log.New(io.Discard, "", 0))
+}
+
+// eventually polls check for up to a second.
+func eventually(t *testing.T, what string, check func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if check() {
+			return
+		}
+	}
+	t.Fatal("timed out waiting for " + what)
+}
+
+func TestBookCacheRoundTrip(t *testing.T) {
+	tiers := []struct {
+		name                        string
+		disableLocal, disableShared bool
+	}{
+		{"both tiers", false, false},
+		{"redis only", true, false},
+		{"local only", false, true},
+	}
+	for _, tier := range tiers {
+		t.Run(tier.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := testConfig()
+			cfg.DisableLocal, cfg.DisableShared = tier.disableLocal, tier.disableShared
+			r := testService(t, miniredis.RunT(t), cfg)
+
+			v1 := &models.Book{BookId: "b1", Title: "Dune", Version: 1}
+			v2 := &models.Book{BookId: "b1", Title: "Dune Messiah", Version: 2}
+			get := func(want *models.Book) {
+				t.Helper()
+				book, stale, err := r.GetBook(ctx, "b1")
+				if err != nil {
+					t.Fatalf("GetBook: %v", err)
+				}
+				if stale {
+					t.Error("a fresh book was reported stale")
+				}
+				if (book == nil) != (want == nil) || book != nil && *book != *want {
+					t.Fatalf("got %+v, want %+v", book, want)
+				}
+			}
+
+			if err := r.CacheBook(ctx, v1); err != nil {
+				t.Fatalf("CacheBook: %v", err)
+			}
+			get(v1)
+			if err := r.InvalidateBook(ctx, "b1", 2); err != nil {
+				t.Fatalf("InvalidateBook: %v", err)
+			}
+			get(nil)
+			// A read of version 1 that finishes after the change is fenced off.
+			if err := r.CacheBook(ctx, v1); err != nil {
+				t.Fatalf("CacheBook: %v", err)
+			}
+			get(nil)
+			if err := r.CacheBook(ctx, v2); err != nil {
+				t.Fatalf("CacheBook: %v", err)
+			}
+			get(v2)
+		})
+	}
+}
+
+func TestStaleBook(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.BookTTL = 10 * time.Millisecond
+	r := testService(t, miniredis.RunT(t), cfg)
+
+	if err := r.CacheBook(ctx, &models.Book{BookId: "b1", Version: 1}); err != nil {
+		t.Fatalf("CacheBook: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	book, stale, err := r.GetBook(ctx, "b1")
+	if err != nil || book == nil || !stale {
+		t.Fatalf("got %+v, stale %v, %v; want the book, stale", book, stale, err)
+	}
+}
+
+func TestInvalidationReachesOtherReplicas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mr := miniredis.RunT(t)
+	writer, reader := testService(t, mr, testConfig()), testService(t, mr, testConfig())
+	go reader.ListenInvalidations(ctx)
+	eventually(t, "the subscription", func() bool {
+		return mr.PubSubNumSub("test:" + invalidationChannel)["test:"+invalidationChannel] == 1
+	})
+
+	if err := reader.CacheBook(ctx, &models.Book{BookId: "b1", Version: 1}); err != nil {
+		t.Fatalf("CacheBook: %v", err)
+	}
+	if err := writer.InvalidateBook(ctx, "b1", 2); err != nil {
+		t.Fatalf("InvalidateBook: %v", err)
+	}
+	eventually(t, "the invalidation", func() bool {
+		entry := reader.local.get("b1")
+		return entry != nil && entry.Book == nil
+	})
+}
+
+func TestQueryCache(t *testing.T) {
+	ctx := context.Background()
+	r := testService(t, miniredis.RunT(t), testConfig())
+	get := func() bool {
+		t.Helper()
+		var result []string
+		hit, err := r.GetQuery(ctx, "all", "h1", &result)
+		if err != nil {
+			t.Fatalf("GetQuery: %v", err)
+		}
+		return hit
+	}
+
+	epoch, err := r.QueryEpoch(ctx)
+	if err != nil {
+		t.Fatalf("QueryEpoch: %v", err)
+	}
+	if err := r.StoreQuery(ctx, "all", "h1", epoch, []string{"b1"}, []string{"book:b1"}); err != nil {
+		t.Fatalf("StoreQuery: %v", err)
+	}
+	if !get() {
+		t.Fatal("stored query missed")
+	}
+	if err := r.InvalidateQueries(ctx, []string{"book:b1"}); err != nil {
+		t.Fatalf("InvalidateQueries: %v", err)
+	}
+	if get() {
+		t.Fatal("invalidated query hit")
+	}
+	// A result read before the invalidation may lack the change.
+	if err := r.StoreQuery(ctx, "all", "h1", epoch, []string{"b1"}, []string{"book:b1"}); err != nil {
+		t.Fatalf("StoreQuery: %v", err)
+	}
+	if get() {
+		t.Fatal("query read under an old epoch was stored")
+	}
+}
+
+func TestCatalogModifiedOnlyMovesForward(t *testing.T) {
+	ctx := context.Background()
+	r := testService(t, miniredis.RunT(t), testConfig())
+	later := time.Date(2024, 6, 1, 12, 0, 0, 1, time.UTC)
+
+	for _, modifiedAt := range []time.Time{later, later.Add(-time.Second)} {
+		if err := r.SetCatalogModified(ctx, modifiedAt); err != nil {
+			t.Fatalf("SetCatalogModified: %v", err)
+		}
+	}
+	if got, err := r.GetCatalogModified(ctx); err != nil || !got.Equal(later) {
+		t.Fatalf("got %s, %v; want %s", got, err, later)
+	}
+}
+
+func TestEventsAreNamespaced(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := testService(t, mr, testConfig())
+
+	live := r.SubscribeEvents(ctx, "books:events:live")
+	defer live.Close()
+	if _, err := live.Receive(ctx); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	id, err := r.PublishEvent(ctx, "books:events", "books:events:live", 100, &models.BookEvent{EventId: "e1", Type: models.EventBookCreated, BookId: "b1"})
+	if err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+	if !mr.Exists("test:books:events") || mr.Exists("books:events") {
+		t.Fatalf("the stream is not under the key prefix: %v", mr.Keys())
+	}
+
+	message, err := live.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatalf("ReceiveMessage: %v", err)
+	}
+	if message.Channel != "test:books:events:live" {
+		t.Errorf("announced on %s", message.Channel)
+	}
+
+	events, err := r.ReadEventsAfter(ctx, "books:events", "0", 10)
+	if err != nil || len(events) != 1 || events[0].Id != id || events[0].Event.EventId != "e1" {
+		t.Fatalf("ReadEventsAfter: got %+v, %v", events, err)
+	}
+	first, last, err := r.StreamBounds(ctx, "books:events")
+	if err != nil || first != id || last != id {
+		t.Fatalf("StreamBounds: got %s, %s, %v", first, last, err)
+	}
+}
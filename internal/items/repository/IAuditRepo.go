@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	IAuditRepo interface {
+		AppendAuditEntry(context.Context, *models.AuditEntry) error
+		QueryAuditLog(context.Context, *models.QueryAuditLogRequest) (*models.QueryAuditLogResponse, error)
+		VerifyAuditLog(context.Context) (*models.VerifyAuditLogResponse, error)
+	}
+)
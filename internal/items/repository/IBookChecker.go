@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// IBookChecker vets a book the storage works out itself, by applying a
+	// patch or going back to an earlier revision. The storage calls it
+	// inside the transaction, before the book is written.
+	IBookChecker interface {
+		CheckBook(*models.Book) error
+	}
+)
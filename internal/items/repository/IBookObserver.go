@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	// IBookObserver is told about every committed book mutation, with the
+	// book as the mutation's transaction found it, nil for a new book, and
+	// as it left it.
+	IBookObserver interface {
+		BookChanged(ctx context.Context, before, after *models.Book)
+	}
+)
@@ -10,11 +10,18 @@ type (
 	IBookRepo interface {
 		CreateBook(context.Context, *models.CreateBookRequest) (*models.Book, error)
 		UpdateBook(context.Context, *models.UpdateBookRequest) (*models.Book, error)
+		PatchBook(context.Context, *models.PatchBookRequest) (*models.Book, error)
 		GetBookById(context.Context, *models.GetBookByIdRequest) (*models.Book, error)
 		GetAllBooks(context.Context, *models.GetAllBooksRequest) (*models.GetSeveralResponse, error)
 		GetBooksByAuthor(context.Context, *models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error)
 		GetBooksByName(context.Context, *models.GetBooksByNameRequest) (*models.GetSeveralResponse, error)
-		SearchBooks(context.Context, *models.SearchBooksRequest) (*models.GetSeveralResponse, error)
-		DeleteBookById(context.Context,*models.DeleteBookByIdRequest) error
+		SearchBooks(context.Context, *models.SearchBooksRequest) (*models.SearchBooksResponse, error)
+		DeleteBookById(context.Context, *models.DeleteBookByIdRequest) error
+		GetCatalogVersion(context.Context) (*models.CatalogVersion, error)
+		ListTrash(context.Context, *models.ListTrashRequest) (*models.GetSeveralResponse, error)
+		RestoreBook(context.Context, *models.RestoreBookRequest) (*models.Book, error)
+		PurgeDeletedBooks(context.Context, *models.PurgeDeletedBooksRequest) (*models.PurgeDeletedBooksResponse, error)
+		GetBookHistory(context.Context, *models.GetBookHistoryRequest) (*models.GetBookHistoryResponse, error)
+		RevertBook(context.Context, *models.RevertBookRequest) (*models.Book, error)
 	}
 )
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	IOutboxRepo interface {
+		ClaimOutboxEvents(context.Context, *models.ClaimOutboxEventsRequest) ([]*models.OutboxEvent, error)
+		MarkOutboxPublished(context.Context, *models.MarkOutboxPublishedRequest) error
+		MarkOutboxFailed(context.Context, *models.MarkOutboxFailedRequest) error
+		PurgePublishedOutboxEvents(context.Context, *models.PurgePublishedOutboxEventsRequest) (*models.PurgePublishedOutboxEventsResponse, error)
+	}
+)
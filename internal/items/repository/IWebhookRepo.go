@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+type (
+	IWebhookRepo interface {
+		CreateWebhook(context.Context, *models.CreateWebhookRequest) (*models.Webhook, error)
+		UpdateWebhook(context.Context, *models.UpdateWebhookRequest) (*models.Webhook, error)
+		GetWebhook(context.Context, *models.GetWebhookRequest) (*models.Webhook, error)
+		ListWebhooks(context.Context) (*models.ListWebhooksResponse, error)
+		DeleteWebhook(context.Context, *models.DeleteWebhookRequest) error
+		ListWebhookDeliveries(context.Context, *models.ListWebhookDeliveriesRequest) (*models.ListWebhookDeliveriesResponse, error)
+		ReplayWebhookDeliveries(context.Context, *models.ReplayWebhookDeliveriesRequest) (*models.ReplayWebhookDeliveriesResponse, error)
+		ClaimWebhookDeliveries(context.Context, *models.ClaimWebhookDeliveriesRequest) ([]*models.PendingWebhookDelivery, error)
+		MarkWebhookDelivered(context.Context, *models.MarkWebhookDeliveredRequest) error
+		MarkWebhookFailed(context.Context, *models.MarkWebhookFailedRequest) error
+	}
+)
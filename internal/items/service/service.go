@@ -9,22 +9,37 @@ import (
 
 type (
 	Service struct {
-		storage repository.IBookRepo
+		storage   repository.IBookRepo
+		validator *Validator
 	}
 )
 
-func New(storage repository.IBookRepo) repository.IBookRepo {
+// New validates requests with validator before handing them to storage.
+// Books the storage works out from a patch or a revision are left to it,
+// since they only exist inside its transaction; it should be given the same
+// validator as its repository.IBookChecker.
+func New(storage repository.IBookRepo, validator *Validator) repository.IBookRepo {
 	return &Service{
-		storage: storage,
+		storage:   storage,
+		validator: validator,
 	}
 }
 
 func (s *Service) CreateBook(ctx context.Context, req *models.CreateBookRequest) (*models.Book, error) {
+	if err := s.validator.CreateBook(req); err != nil {
+		return nil, err
+	}
 	return s.storage.CreateBook(ctx, req)
 }
 func (s *Service) UpdateBook(ctx context.Context, req *models.UpdateBookRequest) (*models.Book, error) {
+	if err := s.validator.UpdateBook(req); err != nil {
+		return nil, err
+	}
 	return s.storage.UpdateBook(ctx, req)
 }
+func (s *Service) PatchBook(ctx context.Context, req *models.PatchBookRequest) (*models.Book, error) {
+	return s.storage.PatchBook(ctx, req)
+}
 func (s *Service) GetBookById(ctx context.Context, req *models.GetBookByIdRequest) (*models.Book, error) {
 	return s.storage.GetBookById(ctx, req)
 }
@@ -37,12 +52,30 @@ func (s *Service) GetBooksByAuthor(ctx context.Context, req *models.GetBooksByAu
 func (s *Service) GetBooksByName(ctx context.Context, req *models.GetBooksByNameRequest) (*models.GetSeveralResponse, error) {
 	return s.storage.GetBooksByName(ctx, req)
 }
-func (s *Service) SearchBooks(ctx context.Context, req *models.SearchBooksRequest) (*models.GetSeveralResponse, error) {
+func (s *Service) SearchBooks(ctx context.Context, req *models.SearchBooksRequest) (*models.SearchBooksResponse, error) {
 	return s.storage.SearchBooks(ctx, req)
 }
 func (s *Service) DeleteBookById(ctx context.Context, req *models.DeleteBookByIdRequest) error {
 	return s.storage.DeleteBookById(ctx, req)
 }
+func (s *Service) GetCatalogVersion(ctx context.Context) (*models.CatalogVersion, error) {
+	return s.storage.GetCatalogVersion(ctx)
+}
+func (s *Service) ListTrash(ctx context.Context, req *models.ListTrashRequest) (*models.GetSeveralResponse, error) {
+	return s.storage.ListTrash(ctx, req)
+}
+func (s *Service) RestoreBook(ctx context.Context, req *models.RestoreBookRequest) (*models.Book, error) {
+	return s.storage.RestoreBook(ctx, req)
+}
+func (s *Service) PurgeDeletedBooks(ctx context.Context, req *models.PurgeDeletedBooksRequest) (*models.PurgeDeletedBooksResponse, error) {
+	return s.storage.PurgeDeletedBooks(ctx, req)
+}
+func (s *Service) GetBookHistory(ctx context.Context, req *models.GetBookHistoryRequest) (*models.GetBookHistoryResponse, error) {
+	return s.storage.GetBookHistory(ctx, req)
+}
+func (s *Service) RevertBook(ctx context.Context, req *models.RevertBookRequest) (*models.Book, error) {
+	return s.storage.RevertBook(ctx, req)
+}
 
 /*
 	CreateBook(*models.CreateBookRequest) (*models.Book, error)
@@ -51,6 +84,6 @@ func (s *Service) DeleteBookById(ctx context.Context, req *models.DeleteBookById
 	GetAllBooks(*models.GetAllBooksRequest) (*models.GetSeveralResponse, error)
 	GetBooksByAuthor(*models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error)
 	GetBooksByName(*models.GetBooksByNameRequest) (*models.GetSeveralResponse, error)
-	SearchBooks(*models.SearchBooksRequest) (*models.GetSeveralResponse, error)
+	SearchBooks(*models.SearchBooksRequest) (*models.SearchBooksResponse, error)
 	DeleteBookById(context.Context,*models.DeleteBookByIdRequest) error
 */
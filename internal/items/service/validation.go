@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+type (
+	// Validator normalizes and checks book payloads before they reach
+	// storage. It has no dependencies beyond its rules, so it can be used and
+	// tested on its own.
+	Validator struct {
+		rules config.ValidationConfig
+		now   func() time.Time
+	}
+)
+
+func NewValidator(rules config.ValidationConfig) *Validator {
+	return &Validator{
+		rules: rules,
+		now:   time.Now,
+	}
+}
+
+// CreateBook normalizes req in place and reports every rule it breaks.
+func (v *Validator) CreateBook(req *models.CreateBookRequest) error {
+	return v.fields(&req.Title, &req.Author, req.PublishedYear)
+}
+
+// UpdateBook checks a full replacement, so it has the same rules as CreateBook.
+func (v *Validator) UpdateBook(req *models.UpdateBookRequest) error {
+	return v.fields(&req.Title, &req.Author, req.PublishedYear)
+}
+
+// CheckBook normalizes and checks a book the storage produced by applying a
+// patch or going back to a revision. It makes Validator a
+// repository.IBookChecker.
+func (v *Validator) CheckBook(book *models.Book) error {
+	return v.fields(&book.Title, &book.Author, book.PublishedYear)
+}
+
+func (v *Validator) fields(title, author *string, year int) error {
+	*title = normalize(*title)
+	*author = normalize(*author)
+
+	var details []apperrors.Detail
+	details = append(details, v.text("title", *title, v.rules.MaxTitleLength)...)
+	details = append(details, v.text("author", *author, v.rules.MaxAuthorLength)...)
+	details = append(details, v.year(year)...)
+	return invalidBook(details)
+}
+
+func (v *Validator) text(field, value string, maxLength int) []apperrors.Detail {
+	if value == "" {
+		return []apperrors.Detail{{Field: field, Reason: "is required"}}
+	}
+
+	var details []apperrors.Detail
+	if utf8.RuneCountInString(value) > maxLength {
+		details = append(details, apperrors.Detail{Field: field, Reason: fmt.Sprintf("must be at most %d characters", maxLength)})
+	}
+	if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		details = append(details, apperrors.Detail{Field: field, Reason: "must not contain control characters"})
+	}
+	return details
+}
+
+// year must fall inside the configured range, whose upper bound defaults to
+// the current year. Zero is what a missing published_year decodes to, so it
+// is reported as missing.
+func (v *Validator) year(year int) []apperrors.Detail {
+	if year == 0 {
+		return []apperrors.Detail{{Field: "published_year", Reason: "is required"}}
+	}
+	maxYear := v.rules.MaxPublishedYear
+	if maxYear == 0 {
+		maxYear = v.now().Year()
+	}
+	if year < v.rules.MinPublishedYear || year > maxYear {
+		return []apperrors.Detail{{
+			Field:  "published_year",
+			Reason: fmt.Sprintf("must be between %d and %d", v.rules.MinPublishedYear, maxYear),
+		}}
+	}
+	return nil
+}
+
+// normalize trims the value, folds runs of whitespace into one space and
+// converts it to Unicode NFC so equal strings compare equal in Postgres.
+func normalize(value string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(value), " "))
+}
+
+func invalidBook(details []apperrors.Detail) error {
+	if len(details) == 0 {
+		return nil
+	}
+	return apperrors.WithDetails(apperrors.Validation, details, "book is invalid")
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func testValidator() *Validator {
+	v := NewValidator(config.ValidationConfig{
+		MinPublishedYear: 1450,
+		MaxTitleLength:   5,
+		MaxAuthorLength:  5,
+	})
+	v.now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	return v
+}
+
+// details returns the details of a validation error, or nil for no error.
+func details(t *testing.T, err error) []apperrors.Detail {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Kind != apperrors.Validation {
+		t.Fatalf("got %v, want a validation error", err)
+	}
+	return appErr.Details
+}
+
+func TestCreateBookNormalizes(t *testing.T) {
+	tests := []struct {
+		name       string
+		title      string
+		wantTitle  string
+		author     string
+		wantAuthor string
+	}{
+		{"trims", "  Dune\t", "Dune", "\nLeo ", "Leo"},
+		{"folds whitespace", "a \t\n b", "a b", "x  y", "x y"},
+		// "e" followed by a combining acute accent becomes a single rune.
+		{"composes to NFC", "cafe\u0301", "caf\u00e9", "Leo", "Leo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.CreateBookRequest{Title: tt.title, Author: tt.author, PublishedYear: 2000}
+			if err := testValidator().CreateBook(req); err != nil {
+				t.Fatalf("CreateBook: %v", err)
+			}
+			if req.Title != tt.wantTitle || req.Author != tt.wantAuthor {
+				t.Errorf("got %q by %q, want %q by %q", req.Title, req.Author, tt.wantTitle, tt.wantAuthor)
+			}
+		})
+	}
+}
+
+func TestCreateBookRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		author string
+		year   int
+		want   []apperrors.Detail
+	}{
+		{"valid", "Dune", "Leo", 1965, nil},
+		{"missing title", " ", "Leo", 1965, []apperrors.Detail{{Field: "title", Reason: "is required"}}},
+		{"missing author", "Dune", "", 1965, []apperrors.Detail{{Field: "author", Reason: "is required"}}},
+		// Five runes but more than five bytes.
+		{"limit counts runes", "\u00c5\u00c5\u00c5\u00c5\u00c5", "Leo", 1965, nil},
+		{"too long", "Dunes!", "Leo", 1965, []apperrors.Detail{{Field: "title", Reason: "must be at most 5 characters"}}},
+		{"control character", "a\x00b", "Leo", 1965, []apperrors.Detail{{Field: "title", Reason: "must not contain control characters"}}},
+		{"missing year", "Dune", "Leo", 0, []apperrors.Detail{{Field: "published_year", Reason: "is required"}}},
+		{"lowest year", "Dune", "Leo", 1450, nil},
+		{"before range", "Dune", "Leo", 1449, []apperrors.Detail{{Field: "published_year", Reason: "must be between 1450 and 2024"}}},
+		{"current year", "Dune", "Leo", 2024, nil},
+		{"after range", "Dune", "Leo", 2025, []apperrors.Detail{{Field: "published_year", Reason: "must be between 1450 and 2024"}}},
+		{"every field", "", "Leonid", -1, []apperrors.Detail{
+			{Field: "title", Reason: "is required"},
+			{Field: "author", Reason: "must be at most 5 characters"},
+			{Field: "published_year", Reason: "must be between 1450 and 2024"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testValidator().CreateBook(&models.CreateBookRequest{Title: tt.title, Author: tt.author, PublishedYear: tt.year})
+			if got := details(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguredMaxYear(t *testing.T) {
+	v := testValidator()
+	v.rules.MaxPublishedYear = 1900
+	err := v.UpdateBook(&models.UpdateBookRequest{Title: "Dune", Author: "Leo", PublishedYear: 1965})
+	want := []apperrors.Detail{{Field: "published_year", Reason: "must be between 1450 and 1900"}}
+	if got := details(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckBookNormalizesInPlace(t *testing.T) {
+	book := &models.Book{Title: " Dune ", Author: "Leo", PublishedYear: 1965}
+	if err := testValidator().CheckBook(book); err != nil {
+		t.Fatalf("CheckBook: %v", err)
+	}
+	if book.Title != "Dune" {
+		t.Errorf("title %q was not trimmed", book.Title)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+const (
+	secretPrefix    = "whsec_"
+	minSecretLength = 16
+)
+
+var webhookEvents = map[string]bool{
+	models.EventBookCreated: true,
+	models.EventBookUpdated: true,
+	models.EventBookDeleted: true,
+}
+
+type (
+	WebhookService struct {
+		storage repository.IWebhookRepo
+	}
+)
+
+func NewWebhookService(storage repository.IWebhookRepo) repository.IWebhookRepo {
+	return &WebhookService{
+		storage: storage,
+	}
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	if req.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		req.Secret = secret
+	}
+	if err := validateWebhook(&req.URL, &req.Events, req.Secret); err != nil {
+		return nil, err
+	}
+	return s.storage.CreateWebhook(ctx, req)
+}
+func (s *WebhookService) UpdateWebhook(ctx context.Context, req *models.UpdateWebhookRequest) (*models.Webhook, error) {
+	if err := validateWebhook(&req.URL, &req.Events, req.Secret); err != nil {
+		return nil, err
+	}
+	return s.storage.UpdateWebhook(ctx, req)
+}
+func (s *WebhookService) GetWebhook(ctx context.Context, req *models.GetWebhookRequest) (*models.Webhook, error) {
+	return s.storage.GetWebhook(ctx, req)
+}
+func (s *WebhookService) ListWebhooks(ctx context.Context) (*models.ListWebhooksResponse, error) {
+	return s.storage.ListWebhooks(ctx)
+}
+func (s *WebhookService) DeleteWebhook(ctx context.Context, req *models.DeleteWebhookRequest) error {
+	return s.storage.DeleteWebhook(ctx, req)
+}
+func (s *WebhookService) ListWebhookDeliveries(ctx context.Context, req *models.ListWebhookDeliveriesRequest) (*models.ListWebhookDeliveriesResponse, error) {
+	switch req.Status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return nil, apperrors.WithDetails(apperrors.Invalid, []apperrors.Detail{
+			{Param: "status", Reason: "must be one of pending, delivered, dead"},
+		}, "invalid delivery status %q", req.Status)
+	}
+	return s.storage.ListWebhookDeliveries(ctx, req)
+}
+func (s *WebhookService) ReplayWebhookDeliveries(ctx context.Context, req *models.ReplayWebhookDeliveriesRequest) (*models.ReplayWebhookDeliveriesResponse, error) {
+	return s.storage.ReplayWebhookDeliveries(ctx, req)
+}
+func (s *WebhookService) ClaimWebhookDeliveries(ctx context.Context, req *models.ClaimWebhookDeliveriesRequest) ([]*models.PendingWebhookDelivery, error) {
+	return s.storage.ClaimWebhookDeliveries(ctx, req)
+}
+func (s *WebhookService) MarkWebhookDelivered(ctx context.Context, req *models.MarkWebhookDeliveredRequest) error {
+	return s.storage.MarkWebhookDelivered(ctx, req)
+}
+func (s *WebhookService) MarkWebhookFailed(ctx context.Context, req *models.MarkWebhookFailedRequest) error {
+	return s.storage.MarkWebhookFailed(ctx, req)
+}
+
+// validateWebhook normalizes the target and the event filter in place. An
+// empty secret is allowed because updates keep the stored one.
+func validateWebhook(target *string, events *[]string, secret string) error {
+	var details []apperrors.Detail
+
+	*target = strings.TrimSpace(*target)
+	if parsed, err := url.Parse(*target); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		details = append(details, apperrors.Detail{Field: "url", Reason: "must be an absolute http or https URL"})
+	}
+
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, event := range *events {
+		event = strings.TrimSpace(event)
+		if !webhookEvents[event] {
+			details = append(details, apperrors.Detail{Field: "events", Term: event, Reason: "is not a known event type"})
+			continue
+		}
+		if !seen[event] {
+			seen[event] = true
+			normalized = append(normalized, event)
+		}
+	}
+	sort.Strings(normalized)
+	*events = normalized
+
+	if secret != "" && len(secret) < minSecretLength {
+		details = append(details, apperrors.Detail{Field: "secret", Reason: "must be at least 16 characters"})
+	}
+
+	if len(details) == 0 {
+		return nil
+	}
+	return apperrors.WithDetails(apperrors.Validation, details, "webhook is invalid")
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// auditLockKey serializes appends so every entry chains onto the one before.
+const auditLockKey = 0x617564697400
+
+// genesisHash is the prev_hash of the very first entry.
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+var auditColumns = []string{
+	"id", "occurred_at", "actor", "ip", "method", "route", "request_id",
+	"operation", "book_id", "diff", "status", "outcome", "error", "prev_hash", "hash",
+}
+
+type AuditStorage struct {
+	postgres     *sql.DB
+	queryBuilder sq.StatementBuilderType
+	cfg          *config.Config
+	logger       *log.Logger
+}
+
+func NewAuditStorage(postgres *sql.DB, queryBuilder sq.StatementBuilderType, cfg *config.Config, logger *log.Logger) repository.IAuditRepo {
+	return &AuditStorage{
+		postgres:     postgres,
+		queryBuilder: queryBuilder,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// AppendAuditEntry stamps entry with the current time, chains it onto the
+// last entry and stores it.
+func (s *AuditStorage) AppendAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	tx, err := s.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Println("Error while starting a transaction")
+		return dbError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", auditLockKey); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+
+	query, args, err := s.queryBuilder.Select("hash").
+		From(s.cfg.AuditTable).
+		OrderBy("id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	entry.PrevHash = genesisHash
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&entry.PrevHash); err != nil && err != sql.ErrNoRows {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+
+	// Postgres keeps microseconds; hashing anything finer would not verify.
+	entry.OccurredAt = time.Now().UTC().Truncate(time.Microsecond)
+	// The free text comes from clients, and Postgres refuses some of it.
+	entry.Actor = auditText(entry.Actor)
+	entry.Route = auditText(entry.Route)
+	entry.BookId = auditText(entry.BookId)
+	entry.Error = auditText(entry.Error)
+	var diff []byte
+	if len(entry.Diff) == 0 {
+		entry.Diff = nil
+	} else if diff, err = json.Marshal(entry.Diff); err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	entry.Hash, err = auditHash(entry)
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	query, args, err = s.queryBuilder.Insert(s.cfg.AuditTable).
+		Columns(auditColumns[1:]...).
+		Values(entry.OccurredAt, nullString(entry.Actor), entry.IP, entry.Method, entry.Route, entry.RequestId,
+			nullString(entry.Operation), nullString(entry.BookId), nullString(string(diff)), entry.Status, entry.Outcome,
+			nullString(entry.Error), entry.PrevHash, entry.Hash).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&entry.Id); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Println("Error while commiting transaction :", err.Error())
+		return dbError(err)
+	}
+	return nil
+}
+
+func (s *AuditStorage) QueryAuditLog(ctx context.Context, req *models.QueryAuditLogRequest) (*models.QueryAuditLogResponse, error) {
+	if req.Cursor != "" {
+		return nil, apperrors.New(apperrors.Invalid, "the audit log only supports page based paging")
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := clampPageLimit(req.Limit, s.cfg.Server.MaxPageSize)
+
+	where := sq.And{}
+	if req.Actor != "" {
+		where = append(where, sq.Eq{"actor": req.Actor})
+	}
+	if !req.From.IsZero() {
+		where = append(where, sq.GtOrEq{"occurred_at": req.From})
+	}
+	if !req.To.IsZero() {
+		where = append(where, sq.Lt{"occurred_at": req.To})
+	}
+
+	countQuery, countArgs, err := s.queryBuilder.Select("COUNT(*)").
+		From(s.cfg.AuditTable).
+		Where(where).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	var total int64
+	if err := s.postgres.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	query, args, err := s.queryBuilder.Select(auditColumns...).
+		From(s.cfg.AuditTable).
+		Where(where).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64((page - 1) * limit)).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	return &models.QueryAuditLogResponse{
+		Entries: entries,
+		Pagination: &models.Pagination{
+			Total:   total,
+			Page:    page,
+			Limit:   limit,
+			HasNext: int64(page*limit) < total,
+		},
+	}, nil
+}
+
+// VerifyAuditLog walks the whole chain and reports the first entry that was
+// changed, removed or inserted after the fact.
+func (s *AuditStorage) VerifyAuditLog(ctx context.Context) (*models.VerifyAuditLogResponse, error) {
+	query, args, err := s.queryBuilder.Select(auditColumns...).
+		From(s.cfg.AuditTable).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	response := &models.VerifyAuditLogResponse{Valid: true}
+	prevHash := genesisHash
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		response.Checked++
+
+		hash, err := auditHash(entry)
+		if err != nil {
+			s.logger.Println(err)
+			return nil, err
+		}
+		if entry.PrevHash != prevHash || entry.Hash != hash {
+			response.Valid = false
+			response.BrokenAt = entry.Id
+			break
+		}
+		prevHash = entry.Hash
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	return response, nil
+}
+
+// auditHash is sha256(prev_hash || canonical JSON of the entry). The id is
+// left out because it is only known after the insert.
+func auditHash(entry *models.AuditEntry) (string, error) {
+	payload, err := json.Marshal(struct {
+		OccurredAt string                        `json:"occurred_at"`
+		Actor      string                        `json:"actor"`
+		IP         string                        `json:"ip"`
+		Method     string                        `json:"method"`
+		Route      string                        `json:"route"`
+		RequestId  string                        `json:"request_id"`
+		Operation  string                        `json:"operation"`
+		BookId     string                        `json:"book_id"`
+		Diff       map[string]models.AuditChange `json:"diff"`
+		Status     int                           `json:"status"`
+		Outcome    string                        `json:"outcome"`
+		Error      string                        `json:"error"`
+	}{
+		OccurredAt: entry.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Actor:      entry.Actor,
+		IP:         entry.IP,
+		Method:     entry.Method,
+		Route:      entry.Route,
+		RequestId:  entry.RequestId,
+		Operation:  entry.Operation,
+		BookId:     entry.BookId,
+		Diff:       entry.Diff,
+		Status:     entry.Status,
+		Outcome:    entry.Outcome,
+		Error:      entry.Error,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(entry.PrevHash), payload...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func scanAuditEntry(row rowScanner) (*models.AuditEntry, error) {
+	var (
+		entry                             models.AuditEntry
+		actor, operation, bookId, message sql.NullString
+		diff                              []byte
+	)
+	if err := row.Scan(&entry.Id, &entry.OccurredAt, &actor, &entry.IP, &entry.Method, &entry.Route, &entry.RequestId,
+		&operation, &bookId, &diff, &entry.Status, &entry.Outcome, &message, &entry.PrevHash, &entry.Hash); err != nil {
+		return nil, err
+	}
+	entry.Actor = actor.String
+	entry.Operation = operation.String
+	entry.BookId = bookId.String
+	entry.Error = message.String
+	if len(diff) > 0 {
+		if err := json.Unmarshal(diff, &entry.Diff); err != nil {
+			return nil, err
+		}
+	}
+	return &entry, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// auditText makes value storable as Postgres text, which must be valid UTF-8
+// without NUL characters.
+func auditText(value string) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(value, "\uFFFD"), "\x00", "")
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func testAuditStorage(t *testing.T) (*AuditStorage, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s := NewAuditStorage(db, sq.StatementBuilder.PlaceholderFormat(sq.Dollar), testConfig(), log.New(io.Discard, "", 0))
+	return s.(*AuditStorage), mock
+}
+
+// auditChain appends entries one by one, each onto the hash of the one
+// before as the database would return it, and returns them as stored.
+func auditChain(t *testing.T) []*models.AuditEntry {
+	t.Helper()
+	s, mock := testAuditStorage(t)
+	entries := []*models.AuditEntry{
+		{Actor: "alice", IP: "10.0.0.1", Method: "POST", Route: "/v1/books", RequestId: "r1", Operation: models.RevisionCreate, BookId: testBookId,
+			Diff: map[string]models.AuditChange{"title": {After: "Dune"}}, Status: 201, Outcome: models.AuditSuccess},
+		{IP: "10.0.0.2", Method: "DELETE", Route: "/v1/books/:id", RequestId: "r2", BookId: testBookId, Status: 412, Outcome: models.AuditFailure, Error: "version mismatch"},
+		{Actor: "bob", IP: "10.0.0.3", Method: "PUT", Route: "/v1/books/:id", RequestId: "r3", Operation: models.RevisionUpdate, BookId: testBookId,
+			Diff: map[string]models.AuditChange{"title": {Before: "Dune", After: "Dune Messiah"}}, Status: 200, Outcome: models.AuditSuccess},
+	}
+
+	prevHash := genesisHash
+	for i, entry := range entries {
+		mock.ExpectBegin()
+		// The lock is taken before the last hash is read, so two appends
+		// cannot chain onto the same entry.
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).WithArgs(auditLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+		last := mock.ExpectQuery("SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1")
+		if i == 0 {
+			last.WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+		} else {
+			last.WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(prevHash))
+		}
+		args := make([]driver.Value, len(auditColumns)-1)
+		for j := range args {
+			args[j] = sqlmock.AnyArg()
+		}
+		args[len(args)-2] = prevHash
+		mock.ExpectQuery("INSERT INTO audit_log").WithArgs(args...).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 1))
+		mock.ExpectCommit()
+
+		if err := s.AppendAuditEntry(context.Background(), entry); err != nil {
+			t.Fatalf("AppendAuditEntry: %v", err)
+		}
+		if entry.Id != int64(i+1) || entry.PrevHash != prevHash {
+			t.Fatalf("entry %d chained onto %s, want %s", entry.Id, entry.PrevHash, prevHash)
+		}
+		prevHash = entry.Hash
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func auditRows(t *testing.T, entries []*models.AuditEntry) *sqlmock.Rows {
+	t.Helper()
+	rows := sqlmock.NewRows(auditColumns)
+	for _, entry := range entries {
+		var diff []byte
+		if entry.Diff != nil {
+			var err error
+			if diff, err = json.Marshal(entry.Diff); err != nil {
+				t.Fatal(err)
+			}
+		}
+		rows.AddRow(entry.Id, entry.OccurredAt, nullValue(entry.Actor), entry.IP, entry.Method, entry.Route, entry.RequestId,
+			nullValue(entry.Operation), nullValue(entry.BookId), diff, entry.Status, entry.Outcome, nullValue(entry.Error),
+			entry.PrevHash, entry.Hash)
+	}
+	return rows
+}
+
+func nullValue(value string) driver.Value {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		tamper     func([]*models.AuditEntry) []*models.AuditEntry
+		wantValid  bool
+		wantBroken int64
+	}{
+		{"intact", func(entries []*models.AuditEntry) []*models.AuditEntry { return entries }, true, 0},
+		{"changed field", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			entries[1].Actor = "mallory"
+			return entries
+		}, false, 2},
+		{"changed diff", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			entries[2].Diff["title"] = models.AuditChange{Before: "Dune", After: "Children of Dune"}
+			return entries
+		}, false, 3},
+		// Rehashing a changed entry still breaks the link from the next one.
+		{"rehashed entry", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			entries[0].Status = 500
+			entries[0].Hash, _ = auditHash(entries[0])
+			return entries
+		}, false, 2},
+		{"missing entry", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			return append(entries[:1], entries[2:]...)
+		}, false, 3},
+		{"missing first entry", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			return entries[1:]
+		}, false, 2},
+		// A chain cut short cannot be told from a shorter log.
+		{"missing last entry", func(entries []*models.AuditEntry) []*models.AuditEntry {
+			return entries[:2]
+		}, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.tamper(auditChain(t))
+			s, mock := testAuditStorage(t)
+			mock.ExpectQuery("SELECT (.+) FROM audit_log ORDER BY id").WillReturnRows(auditRows(t, entries))
+
+			got, err := s.VerifyAuditLog(context.Background())
+			if err != nil {
+				t.Fatalf("VerifyAuditLog: %v", err)
+			}
+			if got.Valid != tt.wantValid || got.BrokenAt != tt.wantBroken {
+				t.Errorf("got valid %v broken at %d, want valid %v broken at %d", got.Valid, got.BrokenAt, tt.wantValid, tt.wantBroken)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// Types of cached queries. They name the hit and miss counters, and every
+// entry of a type other than author is tagged with its type.
+const (
+	queryBook   = "book"
+	queryAll    = "all"
+	queryAuthor = "author"
+	queryTitle  = "title"
+	querySearch = "search"
+)
+
+// lockPoll is how often a request waiting on another process's refill of a
+// book looks for it in the cache.
+const lockPoll = 50 * time.Millisecond
+
+// cacheStats counts hits and misses per query type, as <type>_hits and
+// <type>_misses, under "cache" in /debug/vars. Stale books served while
+// they are refreshed are counted as book_stale as well.
+var cacheStats = expvar.NewMap("cache")
+
+func countCache(queryType string, hit bool) {
+	if hit {
+		cacheStats.Add(queryType+"_hits", 1)
+	} else {
+		cacheStats.Add(queryType+"_misses", 1)
+	}
+}
+
+// cacheAside answers query from the cache into result or, on a miss, runs
+// load, which fills result and returns the tags to cache it under. query is
+// the normalized form of the request; equal queries must hash the same.
+// Cache failures only cost a trip to Postgres.
+func (s *Storage) cacheAside(ctx context.Context, queryType string, query interface{}, result interface{}, load func() ([]string, error)) error {
+	data, err := json.Marshal(query)
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	hit, err := s.redis.GetQuery(ctx, queryType, hash, result)
+	if err != nil {
+		s.logger.Println("Error reading cached query from Redis:", err)
+	}
+	countCache(queryType, hit)
+	if hit {
+		return nil
+	}
+
+	epoch, epochErr := s.redis.QueryEpoch(ctx)
+	tags, err := load()
+	if err != nil {
+		return err
+	}
+	if epochErr != nil {
+		s.logger.Println("Error reading query epoch from Redis:", epochErr)
+		return nil
+	}
+	if queryType != queryAuthor {
+		tags = append(tags, "type:"+queryType)
+	}
+	if err := s.redis.StoreQuery(ctx, queryType, hash, epoch, result, tags); err != nil {
+		s.logger.Println("Error caching query in Redis:", err)
+	}
+	return nil
+}
+
+// loadBook reads a book that missed the cache from Postgres and caches it.
+// Concurrent misses on one book share a single read. Across processes, only
+// the holder of the book's Redis lock reads; the others wait up to LockWait
+// for it to fill the cache. The read outlives a caller that gives up, since
+// others may be waiting on it.
+func (s *Storage) loadBook(ctx context.Context, bookId string) (*models.Book, error) {
+	result, err, _ := s.flight.Do(bookId, func() (interface{}, error) {
+		return s.fillBook(ctx, bookId, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	book := *result.(*models.Book)
+	return &book, nil
+}
+
+// refreshBook refills a stale book in the background, once per process and,
+// through the Redis lock, once across processes. Meanwhile the stale copy is
+// served.
+func (s *Storage) refreshBook(ctx context.Context, bookId string) {
+	cacheStats.Add(queryBook+"_stale", 1)
+	go s.flight.Do("refresh:"+bookId, func() (interface{}, error) {
+		return s.fillBook(ctx, bookId, false)
+	})
+}
+
+// fillBook caches a book read from Postgres. Without the lock, it waits for
+// the holder's refill when wait is set and otherwise leaves it to the
+// holder, returning nil. A Redis failure only means going to Postgres.
+func (s *Storage) fillBook(ctx context.Context, bookId string, wait bool) (*models.Book, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cfg.Redis.LockTTL)
+	defer cancel()
+
+	release, locked, err := s.redis.LockBook(ctx, bookId)
+	switch {
+	case err != nil:
+		s.logger.Println("Error locking book in Redis:", err)
+	case locked:
+		defer release()
+	case !wait:
+		return nil, nil
+	default:
+		if book := s.awaitBook(ctx, bookId); book != nil {
+			return book, nil
+		}
+	}
+
+	book, err := s.bookFromPostgres(ctx, bookId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redis.CacheBook(ctx, book); err != nil {
+		s.logger.Println("Error caching book in Redis:", err)
+	}
+	return book, nil
+}
+
+// awaitBook polls the cache for a book another process is refilling, for up
+// to LockWait or until ctx is done.
+func (s *Storage) awaitBook(ctx context.Context, bookId string) *models.Book {
+	ticker := time.NewTicker(lockPoll)
+	defer ticker.Stop()
+	deadline := time.Now().Add(s.cfg.Redis.LockWait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if book, _, _ := s.redis.GetBook(ctx, bookId); book != nil {
+			return book
+		}
+	}
+	return nil
+}
+
+// bookTags tags a cached result with the books in it and their authors.
+func bookTags(books []*models.Book) []string {
+	tags := make([]string, 0, 2*len(books))
+	seen := map[string]bool{}
+	for _, book := range books {
+		tags = append(tags, "book:"+book.BookId)
+		if !seen[book.Author] {
+			seen[book.Author] = true
+			tags = append(tags, "author:"+book.Author)
+		}
+	}
+	return tags
+}
+
+// invalidate drops everything cached that a committed change to a book can
+// have made stale: the book itself, results that contain it, author listings
+// of its old and new author, and, when the change can move the book into or
+// out of a result or reorder one, every result of the query types that
+// depend on what changed. before is nil for a new book. A failure is only
+// logged, since the change itself was made.
+func (s *Storage) invalidate(ctx context.Context, operation string, before, after *models.Book) {
+	if err := s.redis.InvalidateBook(ctx, after.BookId, after.Version); err != nil {
+		s.logger.Println("Error invalidating book in Redis:", err)
+	}
+
+	tags := []string{"book:" + after.BookId, "author:" + after.Author}
+	if before != nil && before.Author != after.Author {
+		tags = append(tags, "author:"+before.Author)
+	}
+	switch {
+	case before == nil, operation == models.RevisionCreate, operation == models.RevisionRestore:
+		// No cached result lists a new or trashed book, so none is tagged
+		// with it, yet it can now belong in any of them.
+		tags = append(tags, "type:"+queryAll, "type:"+queryTitle, "type:"+querySearch)
+	case operation == models.RevisionDelete:
+		// The results the book was in go with its tag, which covers search,
+		// as it only has the best matches. A listing page without it can
+		// still be stale: its total counted the book, and offset pages after
+		// it shift up by one.
+		tags = append(tags, "type:"+queryAll, "type:"+queryTitle)
+	case before.Title != after.Title:
+		tags = append(tags, "type:"+queryAll, "type:"+queryTitle, "type:"+querySearch)
+	case before.Author != after.Author:
+		tags = append(tags, "type:"+queryAll, "type:"+querySearch)
+	case before.PublishedYear != after.PublishedYear:
+		tags = append(tags, "type:"+queryAll)
+	}
+	if err := s.redis.InvalidateQueries(ctx, tags); err != nil {
+		s.logger.Println("Error invalidating cached queries in Redis:", err)
+	}
+}
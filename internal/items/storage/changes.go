@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// recordChangeTx writes everything a book mutation leaves behind besides the
+// row itself: the revision, the outbox event and the webhook deliveries. It
+// must run in the transaction that made the change.
+func (s *Storage) recordChangeTx(ctx context.Context, tx *sql.Tx, operation string, book *models.Book) error {
+	if err := s.recordRevisionTx(ctx, tx, operation, book); err != nil {
+		return err
+	}
+
+	eventType := models.EventBookUpdated
+	switch operation {
+	case models.RevisionCreate:
+		eventType = models.EventBookCreated
+	case models.RevisionDelete:
+		eventType = models.EventBookDeleted
+	}
+	event := &models.BookEvent{
+		EventId:    uuid.New().String(),
+		Type:       eventType,
+		BookId:     book.BookId,
+		Version:    book.Version,
+		OccurredAt: book.UpdatedAt,
+		Book:       book,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+
+	if err := s.enqueueEventTx(ctx, tx, event, payload); err != nil {
+		return err
+	}
+	return s.enqueueDeliveriesTx(ctx, tx, event, payload)
+}
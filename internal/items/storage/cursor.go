@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+)
+
+var errInvalidCursor = apperrors.New(apperrors.Invalid, "invalid or tampered cursor")
+
+// cursor is the keyset position a list page starts from. Values hold the
+// sort key of the boundary row under the Sort ordering, Backward tells which
+// side of it to read.
+type cursor struct {
+	Sort     string        `json:"s"`
+	Values   []interface{} `json:"v"`
+	Backward bool          `json:"b,omitempty"`
+}
+
+func (s *Storage) encodeCursor(c cursor) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.signCursor(encoded), nil
+}
+
+func (s *Storage) decodeCursor(token string) (*cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signCursor(encoded))) {
+		return nil, errInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var c cursor
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&c); err != nil {
+		return nil, errInvalidCursor
+	}
+	return &c, nil
+}
+
+func (s *Storage) signCursor(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Server.CursorSecret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
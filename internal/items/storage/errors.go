@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+)
+
+// dbError translates a database/sql or Postgres failure into a domain error
+// so handlers never have to know about the driver.
+func dbError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.Wrap(apperrors.NotFound, err, "book not found")
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return apperrors.Wrap(apperrors.Unavailable, err, "database is unavailable")
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return apperrors.Wrap(apperrors.Unavailable, err, "database is unavailable")
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		code := string(pqErr.Code)
+		switch {
+		case code == "23505":
+			return apperrors.Wrap(apperrors.Conflict, err, "book already exists")
+		case code == "23503":
+			return apperrors.Wrap(apperrors.Conflict, err, "book is still referenced")
+		case strings.HasPrefix(code, "22"), strings.HasPrefix(code, "23"):
+			return apperrors.Wrap(apperrors.Validation, err, "book was rejected by the database: %s", pqErr.Message)
+		case strings.HasPrefix(code, "08"), strings.HasPrefix(code, "53"), strings.HasPrefix(code, "57P"):
+			return apperrors.Wrap(apperrors.Unavailable, err, "database is unavailable")
+		}
+	}
+	return err
+}
+
+func bookNotFound(bookId string) error {
+	return apperrors.New(apperrors.NotFound, "book %s not found", bookId)
+}
+
+func versionMismatch(bookId string, current int64) error {
+	return apperrors.New(apperrors.PreconditionFailed, "book %s is at version %d", bookId, current)
+}
+
+// missingBookError explains why a versioned write touched no rows: either the
+// book is gone or somebody else changed it first.
+func (s *Storage) missingBookError(ctx context.Context, tx *sql.Tx, bookId string) error {
+	query, args, err := s.queryBuilder.Select(s.cfg.Version).
+		From(s.cfg.TableName).
+		Where(sq.Eq{s.cfg.BookId: bookId}).
+		Where(s.live()).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+
+	var current int64
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&current); err == sql.ErrNoRows {
+		return bookNotFound(bookId)
+	} else if err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return versionMismatch(bookId, current)
+}
+
+// notInTrashError explains why a restore touched no rows.
+func (s *Storage) notInTrashError(ctx context.Context, tx *sql.Tx, bookId string) error {
+	query, args, err := s.queryBuilder.Select("1").
+		From(s.cfg.TableName).
+		Where(sq.Eq{s.cfg.BookId: bookId}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&exists); err == sql.ErrNoRows {
+		return bookNotFound(bookId)
+	} else if err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return apperrors.New(apperrors.Conflict, "book %s is not in the trash", bookId)
+}
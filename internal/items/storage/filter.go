@@ -0,0 +1,297 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// Kinds of filterable field, which decide how values are parsed and which
+// operators apply.
+const (
+	textField = iota
+	numberField
+	uuidField
+)
+
+type (
+	// sortKey is one column of a listing's ORDER BY clause.
+	sortKey struct {
+		Column string
+		Desc   bool
+	}
+	ordering []sortKey
+)
+
+// filterColumns returns the whitelisted filter/sort fields, keyed by the
+// configured column names, and the kind of each one.
+func (s *Storage) filterColumns() map[string]int {
+	return map[string]int{
+		s.cfg.BookId:        uuidField,
+		s.cfg.Title:         textField,
+		s.cfg.Author:        textField,
+		s.cfg.PublishedYear: numberField,
+	}
+}
+
+// parseListQuery turns the filter and sort parameters into a squirrel
+// predicate and an ordering. Terms look like author:eq:Tolstoy and
+// -published_year; all problems are collected into one Invalid error. In a
+// filter value, a backslash escapes the next character, so \, and \| stand
+// for a comma and a bar rather than separating terms and values.
+func (s *Storage) parseListQuery(filter, sort string) (sq.Sqlizer, ordering, error) {
+	var details []apperrors.Detail
+	where, filterDetails := s.parseFilter(filter)
+	details = append(details, filterDetails...)
+	order, sortDetails := s.parseSort(sort)
+	details = append(details, sortDetails...)
+
+	if len(details) > 0 {
+		return nil, nil, apperrors.WithDetails(apperrors.Invalid, details, "invalid filter or sort parameter")
+	}
+	return where, order, nil
+}
+
+func (s *Storage) parseFilter(filter string) (sq.Sqlizer, []apperrors.Detail) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	columns := s.filterColumns()
+	var (
+		predicates sq.And
+		details    []apperrors.Detail
+	)
+	for _, term := range splitUnescaped(filter, ',') {
+		parts := strings.SplitN(strings.TrimSpace(term), ":", 3)
+		if len(parts) != 3 {
+			details = append(details, apperrors.Detail{
+				Param: "filter", Term: term, Reason: "expected field:operator:value",
+			})
+			continue
+		}
+		field, operator, raw := parts[0], parts[1], parts[2]
+
+		kind, ok := columns[field]
+		if !ok {
+			details = append(details, apperrors.Detail{
+				Param: "filter", Term: term, Field: field, Reason: "unknown field",
+			})
+			continue
+		}
+
+		var values []interface{}
+		for _, value := range splitUnescaped(raw, '|') {
+			parsed, reason := parseFilterValue(kind, operator, unescape(value))
+			if reason != "" {
+				details = append(details, apperrors.Detail{
+					Param: "filter", Term: term, Field: field, Operator: operator, Reason: reason,
+				})
+				values = nil
+				break
+			}
+			values = append(values, parsed)
+		}
+		if values == nil {
+			continue
+		}
+
+		predicate, reason := filterPredicate(field, operator, kind, values)
+		if reason != "" {
+			details = append(details, apperrors.Detail{
+				Param: "filter", Term: term, Field: field, Operator: operator, Reason: reason,
+			})
+			continue
+		}
+		predicates = append(predicates, predicate)
+	}
+	return predicates, details
+}
+
+// parseFilterValue parses a value for a field of kind. Ids are checked here,
+// since Postgres would only reject them when the query runs, without saying
+// which term was wrong; like is refused on them by filterPredicate.
+func parseFilterValue(kind int, operator, value string) (interface{}, string) {
+	switch kind {
+	case numberField:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, "value must be an integer"
+		}
+		return number, ""
+	case uuidField:
+		if _, err := uuid.Parse(value); err != nil && operator != "like" {
+			return nil, "value must be a UUID"
+		}
+	}
+	return value, ""
+}
+
+func filterPredicate(column, operator string, kind int, values []interface{}) (sq.Sqlizer, string) {
+	if operator != "in" && len(values) > 1 {
+		return nil, "only the in operator accepts several values"
+	}
+
+	switch operator {
+	case "eq":
+		return sq.Eq{column: values[0]}, ""
+	case "ne":
+		return sq.NotEq{column: values[0]}, ""
+	case "gt":
+		return sq.Gt{column: values[0]}, ""
+	case "gte":
+		return sq.GtOrEq{column: values[0]}, ""
+	case "lt":
+		return sq.Lt{column: values[0]}, ""
+	case "lte":
+		return sq.LtOrEq{column: values[0]}, ""
+	case "in":
+		return sq.Eq{column: values}, ""
+	case "like":
+		if kind != textField {
+			return nil, "like is only supported on text fields"
+		}
+		return sq.ILike{column: "%" + values[0].(string) + "%"}, ""
+	default:
+		return nil, "unknown operator"
+	}
+}
+
+// splitUnescaped splits s at every sep that is not escaped by a backslash,
+// leaving the escapes in the parts.
+func splitUnescaped(s string, sep byte) []string {
+	var (
+		parts []string
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape drops the backslashes splitUnescaped left in, keeping what each
+// one escapes. A trailing backslash is kept as it is.
+func unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func (s *Storage) defaultOrdering() ordering {
+	return ordering{{Column: s.cfg.Title}, {Column: s.cfg.BookId}}
+}
+
+func (s *Storage) parseSort(sort string) (ordering, []apperrors.Detail) {
+	columns := s.filterColumns()
+	var (
+		order   ordering
+		details []apperrors.Detail
+		seen    = map[string]bool{}
+	)
+	if strings.TrimSpace(sort) == "" {
+		sort = s.cfg.Title
+	}
+	for _, term := range strings.Split(sort, ",") {
+		term = strings.TrimSpace(term)
+		key := sortKey{Column: strings.TrimPrefix(term, "-"), Desc: strings.HasPrefix(term, "-")}
+		if _, ok := columns[key.Column]; !ok {
+			details = append(details, apperrors.Detail{
+				Param: "sort", Term: term, Field: key.Column, Reason: "unknown field",
+			})
+			continue
+		}
+		if seen[key.Column] {
+			details = append(details, apperrors.Detail{
+				Param: "sort", Term: term, Field: key.Column, Reason: "field is sorted on twice",
+			})
+			continue
+		}
+		seen[key.Column] = true
+		order = append(order, key)
+	}
+	if !seen[s.cfg.BookId] {
+		order = append(order, sortKey{Column: s.cfg.BookId})
+	}
+	return order, details
+}
+
+// String renders the ordering in the same syntax the sort parameter uses.
+func (o ordering) String() string {
+	terms := make([]string, 0, len(o))
+	for _, key := range o {
+		if key.Desc {
+			terms = append(terms, "-"+key.Column)
+		} else {
+			terms = append(terms, key.Column)
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+func (o ordering) orderBy(backward bool) []string {
+	clauses := make([]string, 0, len(o))
+	for _, key := range o {
+		direction := "ASC"
+		if key.Desc != backward {
+			direction = "DESC"
+		}
+		clauses = append(clauses, key.Column+" "+direction)
+	}
+	return clauses
+}
+
+// after builds the keyset predicate selecting rows strictly past values in
+// the ordering, expanded as (a > x) OR (a = x AND b > y) ... so mixed
+// directions are supported.
+func (o ordering) after(values []interface{}, backward bool) sq.Sqlizer {
+	var or sq.Or
+	for i, key := range o {
+		var and sq.And
+		for j := 0; j < i; j++ {
+			and = append(and, sq.Eq{o[j].Column: values[j]})
+		}
+		if key.Desc != backward {
+			and = append(and, sq.Lt{key.Column: values[i]})
+		} else {
+			and = append(and, sq.Gt{key.Column: values[i]})
+		}
+		or = append(or, and)
+	}
+	return or
+}
+
+func (s *Storage) sortValues(order ordering, book *models.Book) []interface{} {
+	values := make([]interface{}, 0, len(order))
+	for _, key := range order {
+		switch key.Column {
+		case s.cfg.BookId:
+			values = append(values, book.BookId)
+		case s.cfg.Title:
+			values = append(values, book.Title)
+		case s.cfg.Author:
+			values = append(values, book.Author)
+		case s.cfg.PublishedYear:
+			values = append(values, book.PublishedYear)
+		}
+	}
+	return values
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+)
+
+func filterStorage() *Storage {
+	return &Storage{cfg: testConfig()}
+}
+
+// reasons returns the reason of each detail of an Invalid error, or nil for
+// no error.
+func reasons(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Kind != apperrors.Invalid {
+		t.Fatalf("got %v, want an invalid error", err)
+	}
+	var reasons []string
+	for _, detail := range appErr.Details {
+		reasons = append(reasons, detail.Field+": "+detail.Reason)
+	}
+	return reasons
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		wantSQL  string
+		wantArgs []interface{}
+		wantErrs []string
+	}{
+		{"empty", " ", "", nil, nil},
+		{"eq", "author:eq:Tolstoy", "(author = ?)", []interface{}{"Tolstoy"}, nil},
+		{"several terms", "author:ne:Tolstoy, published_year:gte:1860", "(author <> ? AND published_year >= ?)", []interface{}{"Tolstoy", 1860}, nil},
+		{"like", "title:like:war", "(title ILIKE ?)", []interface{}{"%war%"}, nil},
+		{"in", "published_year:in:1865|1869", "(published_year IN (?,?))", []interface{}{1865, 1869}, nil},
+		{"value with colon", "title:eq:Dune: Messiah", "(title = ?)", []interface{}{"Dune: Messiah"}, nil},
+		{"escaped comma and bar", `title:in:War\, Peace|A\|B,author:eq:C\\`, "(title IN (?,?) AND author = ?)", []interface{}{"War, Peace", "A|B", `C\`}, nil},
+		{"book id", "book_id:in:" + testBookId + "|" + testBookId, "(book_id IN (?,?))", []interface{}{testBookId, testBookId}, nil},
+
+		{"missing operator", "author", "", nil, []string{": expected field:operator:value"}},
+		{"unknown field", "isbn:eq:1", "", nil, []string{"isbn: unknown field"}},
+		{"unknown operator", "author:is:Tolstoy", "", nil, []string{"author: unknown operator"}},
+		{"not a number", "published_year:gt:1860s", "", nil, []string{"published_year: value must be an integer"}},
+		{"not a number in a list", "published_year:in:1860|x", "", nil, []string{"published_year: value must be an integer"}},
+		{"several values", "author:eq:A|B", "", nil, []string{"author: only the in operator accepts several values"}},
+		{"like on a number", "published_year:like:18", "", nil, []string{"published_year: like is only supported on text fields"}},
+		{"like on the id", "book_id:like:8d1c", "", nil, []string{"book_id: like is only supported on text fields"}},
+		{"id not a uuid", "book_id:eq:foo", "", nil, []string{"book_id: value must be a UUID"}},
+		{"id not a uuid in a list", "book_id:in:" + testBookId + "|foo", "", nil, []string{"book_id: value must be a UUID"}},
+		{"every problem", "isbn:eq:1,book_id:eq:foo", "", nil, []string{"isbn: unknown field", "book_id: value must be a UUID"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, _, err := filterStorage().parseListQuery(tt.filter, "")
+			if got := reasons(t, err); !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Fatalf("got errors %q, want %q", got, tt.wantErrs)
+			}
+			if err != nil {
+				return
+			}
+			var (
+				sql  string
+				args []interface{}
+			)
+			if where != nil {
+				if sql, args, err = where.ToSql(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if sql != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("got %s %v, want %s %v", sql, args, tt.wantSQL, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		want     string
+		wantErrs []string
+	}{
+		{"default", "", "title,book_id", nil},
+		{"descending", "-published_year", "-published_year,book_id", nil},
+		{"several", "author, -title", "author,-title,book_id", nil},
+		{"id last already", "-book_id", "-book_id", nil},
+		{"id in the middle", "book_id,title", "book_id,title", nil},
+
+		{"unknown field", "isbn", "", []string{"isbn: unknown field"}},
+		{"twice", "title,-title", "", []string{"title: field is sorted on twice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, order, err := filterStorage().parseListQuery("", tt.sort)
+			if got := reasons(t, err); !reflect.DeepEqual(got, tt.wantErrs) {
+				t.Fatalf("got errors %q, want %q", got, tt.wantErrs)
+			}
+			if err == nil && order.String() != tt.want {
+				t.Errorf("got %s, want %s", order, tt.want)
+			}
+		})
+	}
+}
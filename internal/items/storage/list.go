@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// listBooks returns one page of books matching where in the given order,
+// which always ends with book_id so it is stable. Offset paging is used until
+// the client sends a cursor.
+func (s *Storage) listBooks(ctx context.Context, where sq.Sqlizer, order ordering, paging models.Paging) (*models.GetSeveralResponse, error) {
+	limit := s.pageLimit(paging.Limit)
+	if paging.Cursor != "" {
+		return s.listBooksByCursor(ctx, where, order, paging.Cursor, limit)
+	}
+
+	page := paging.Page
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	countQuery, countArgs, err := s.queryBuilder.Select("COUNT(*)").
+		From(s.cfg.TableName).
+		Where(where).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	var total int64
+	if err := s.postgres.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	query, args, err := s.queryBuilder.Select(s.bookColumns()...).
+		From(s.cfg.TableName).
+		Where(where).
+		OrderBy(order.orderBy(false)...).
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	books, err := s.queryBooks(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := &models.Pagination{
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		HasNext: int64(offset+len(books)) < total,
+	}
+	if err := s.setCursors(pagination, order, books, pagination.HasNext, page > 1); err != nil {
+		return nil, err
+	}
+	return &models.GetSeveralResponse{Books: books, Pagination: pagination}, nil
+}
+
+func (s *Storage) listBooksByCursor(ctx context.Context, where sq.Sqlizer, order ordering, token string, limit int) (*models.GetSeveralResponse, error) {
+	position, err := s.decodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	if position.Sort != order.String() || len(position.Values) != len(order) {
+		return nil, errInvalidCursor
+	}
+
+	query, args, err := s.queryBuilder.Select(s.bookColumns()...).
+		From(s.cfg.TableName).
+		Where(where).
+		Where(order.after(position.Values, position.Backward)).
+		OrderBy(order.orderBy(position.Backward)...).
+		Limit(uint64(limit + 1)).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	books, err := s.queryBooks(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(books) > limit
+	if more {
+		books = books[:limit]
+	}
+
+	pagination := &models.Pagination{Limit: limit}
+	if position.Backward {
+		for i, j := 0, len(books)-1; i < j; i, j = i+1, j-1 {
+			books[i], books[j] = books[j], books[i]
+		}
+		pagination.HasNext = true
+		err = s.setCursors(pagination, order, books, true, more)
+	} else {
+		pagination.HasNext = more
+		err = s.setCursors(pagination, order, books, more, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetSeveralResponse{Books: books, Pagination: pagination}, nil
+}
+
+func (s *Storage) setCursors(pagination *models.Pagination, order ordering, books []*models.Book, hasNext, hasPrev bool) error {
+	if len(books) == 0 {
+		return nil
+	}
+	var err error
+	if hasNext {
+		last := books[len(books)-1]
+		pagination.NextCursor, err = s.encodeCursor(cursor{Sort: order.String(), Values: s.sortValues(order, last)})
+		if err != nil {
+			s.logger.Println(err)
+			return err
+		}
+	}
+	if hasPrev {
+		first := books[0]
+		pagination.PrevCursor, err = s.encodeCursor(cursor{Sort: order.String(), Values: s.sortValues(order, first), Backward: true})
+		if err != nil {
+			s.logger.Println(err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) queryBooks(ctx context.Context, query string, args ...interface{}) ([]*models.Book, error) {
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+	return s.scanBooks(rows)
+}
+
+func (s *Storage) scanBooks(rows *sql.Rows) ([]*models.Book, error) {
+	books := []*models.Book{}
+	for rows.Next() {
+		book, err := s.scanBook(rows)
+		if err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	return books, nil
+}
+
+// live matches books that are not in the trash.
+func (s *Storage) live() sq.Sqlizer {
+	return sq.Eq{s.cfg.DeletedAt: nil}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// bookColumns lists the columns every book query selects, in the order
+// bookFields scans them.
+func (s *Storage) bookColumns() []string {
+	return []string{s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublishedYear, s.cfg.Version, s.cfg.UpdatedAt, s.cfg.DeletedAt}
+}
+
+func (s *Storage) bookFields(book *models.Book) []interface{} {
+	return []interface{}{&book.BookId, &book.Author, &book.Title, &book.PublishedYear, &book.Version, &book.UpdatedAt, &book.DeletedAt}
+}
+
+func (s *Storage) scanBook(row rowScanner) (*models.Book, error) {
+	var book models.Book
+	if err := row.Scan(s.bookFields(&book)...); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
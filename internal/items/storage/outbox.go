@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// enqueueEventTx writes event to the outbox. It must run in the transaction
+// that made the change, so the event exists exactly when the change does.
+func (s *Storage) enqueueEventTx(ctx context.Context, tx *sql.Tx, event *models.BookEvent, payload []byte) error {
+	query, args, err := s.queryBuilder.Insert(s.cfg.OutboxTable).
+		Columns("event_id", "event_type", "book_id", "payload").
+		Values(event.EventId, event.Type, event.BookId, string(payload)).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return nil
+}
+
+type OutboxStorage struct {
+	postgres     *sql.DB
+	queryBuilder sq.StatementBuilderType
+	cfg          *config.Config
+	logger       *log.Logger
+}
+
+func NewOutboxStorage(postgres *sql.DB, queryBuilder sq.StatementBuilderType, cfg *config.Config, logger *log.Logger) repository.IOutboxRepo {
+	return &OutboxStorage{
+		postgres:     postgres,
+		queryBuilder: queryBuilder,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// ClaimOutboxEvents leases the oldest due events to the caller and counts the
+// attempt. Events that are not marked before the lease runs out are handed
+// out again, which is what makes delivery at least once.
+func (s *OutboxStorage) ClaimOutboxEvents(ctx context.Context, req *models.ClaimOutboxEventsRequest) ([]*models.OutboxEvent, error) {
+	due := fmt.Sprintf("SELECT id FROM %s WHERE published_at IS NULL AND next_attempt_at <= now() ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED", s.cfg.OutboxTable)
+	query, args, err := s.queryBuilder.Update(s.cfg.OutboxTable).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", req.Lease.Seconds())).
+		Where(sq.Expr("id IN ("+due+")", req.Limit)).
+		Suffix("RETURNING id, attempts, payload").
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	events := []*models.OutboxEvent{}
+	for rows.Next() {
+		var (
+			event   models.OutboxEvent
+			payload []byte
+		)
+		if err := rows.Scan(&event.Id, &event.Attempts, &payload); err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		if err := json.Unmarshal(payload, &event.Event); err != nil {
+			s.logger.Println(err)
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	sort.Slice(events, func(i, j int) bool { return events[i].Id < events[j].Id })
+	return events, nil
+}
+
+func (s *OutboxStorage) MarkOutboxPublished(ctx context.Context, req *models.MarkOutboxPublishedRequest) error {
+	query, args, err := s.queryBuilder.Update(s.cfg.OutboxTable).
+		Set("published_at", sq.Expr("now()")).
+		Set("stream_id", req.StreamId).
+		Set("last_error", nil).
+		Where(sq.Eq{"id": req.Id}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	if _, err := s.postgres.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return nil
+}
+
+func (s *OutboxStorage) MarkOutboxFailed(ctx context.Context, req *models.MarkOutboxFailedRequest) error {
+	query, args, err := s.queryBuilder.Update(s.cfg.OutboxTable).
+		Set("last_error", req.Error).
+		Set("next_attempt_at", req.RetryAt).
+		Where(sq.Eq{"id": req.Id}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	if _, err := s.postgres.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return nil
+}
+
+// PurgePublishedOutboxEvents deletes the oldest events published before
+// PublishedBefore, at most Limit of them. Pending events are never deleted.
+func (s *OutboxStorage) PurgePublishedOutboxEvents(ctx context.Context, req *models.PurgePublishedOutboxEventsRequest) (*models.PurgePublishedOutboxEventsResponse, error) {
+	expired := fmt.Sprintf("SELECT id FROM %s WHERE published_at < ? ORDER BY id LIMIT ?", s.cfg.OutboxTable)
+	query, args, err := s.queryBuilder.Delete(s.cfg.OutboxTable).
+		Where(sq.Expr("id IN ("+expired+")", req.PublishedBefore, req.Limit)).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	res, err := s.postgres.ExecContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	return &models.PurgePublishedOutboxEventsResponse{Purged: purged}, nil
+}
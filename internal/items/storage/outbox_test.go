@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func testOutboxStorage(t *testing.T) (*OutboxStorage, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	s := NewOutboxStorage(db, sq.StatementBuilder.PlaceholderFormat(sq.Dollar), testConfig(), log.New(io.Discard, "", 0))
+	return s.(*OutboxStorage), mock
+}
+
+func TestClaimOutboxEvents(t *testing.T) {
+	s, mock := testOutboxStorage(t)
+	// The claim counts the attempt and hides the rows for the lease, and
+	// skips rows another relay has locked rather than waiting on them.
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"UPDATE outbox SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $1) "+
+			"WHERE id IN (SELECT id FROM outbox WHERE published_at IS NULL AND next_attempt_at <= now() ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED) "+
+			"RETURNING id, attempts, payload")).
+		WithArgs(float64(30), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "attempts", "payload"}).
+			AddRow(8, 1, []byte(`{"event_id":"e8","type":"book.updated"}`)).
+			AddRow(5, 3, []byte(`{"event_id":"e5","type":"book.created"}`)))
+
+	events, err := s.ClaimOutboxEvents(context.Background(), &models.ClaimOutboxEventsRequest{Limit: 2, Lease: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("ClaimOutboxEvents: %v", err)
+	}
+	if len(events) != 2 || events[0].Id != 5 || events[1].Id != 8 {
+		t.Fatalf("got %+v, want events 5 and 8 in order", events)
+	}
+	if events[0].Attempts != 3 || events[0].Event.EventId != "e5" || events[0].Event.Type != models.EventBookCreated {
+		t.Errorf("got %+v %+v", events[0], events[0].Event)
+	}
+}
+
+func TestClaimOutboxEventsNoneDue(t *testing.T) {
+	s, mock := testOutboxStorage(t)
+	mock.ExpectQuery("UPDATE outbox").WillReturnRows(sqlmock.NewRows([]string{"id", "attempts", "payload"}))
+
+	events, err := s.ClaimOutboxEvents(context.Background(), &models.ClaimOutboxEventsRequest{Limit: 10, Lease: time.Second})
+	if err != nil || events == nil || len(events) != 0 {
+		t.Fatalf("got %v, %v; want no events", events, err)
+	}
+}
+
+func TestClaimOutboxEventsDatabaseDown(t *testing.T) {
+	s, mock := testOutboxStorage(t)
+	mock.ExpectQuery("UPDATE outbox").WillReturnError(context.DeadlineExceeded)
+
+	_, err := s.ClaimOutboxEvents(context.Background(), &models.ClaimOutboxEventsRequest{Limit: 10, Lease: time.Second})
+	if err == nil {
+		t.Fatal("a failed claim returned no error")
+	}
+}
+
+func TestMarkOutboxPublished(t *testing.T) {
+	s, mock := testOutboxStorage(t)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET published_at = now(), stream_id = $1, last_error = $2 WHERE id = $3")).
+		WithArgs("1718000000000-0", nil, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := s.MarkOutboxPublished(context.Background(), &models.MarkOutboxPublishedRequest{Id: 5, StreamId: "1718000000000-0"}); err != nil {
+		t.Fatalf("MarkOutboxPublished: %v", err)
+	}
+}
+
+func TestMarkOutboxFailed(t *testing.T) {
+	s, mock := testOutboxStorage(t)
+	retryAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET last_error = $1, next_attempt_at = $2 WHERE id = $3")).
+		WithArgs("redis: connection refused", retryAt, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.MarkOutboxFailed(context.Background(), &models.MarkOutboxFailedRequest{Id: 5, Error: "redis: connection refused", RetryAt: retryAt})
+	if err != nil {
+		t.Fatalf("MarkOutboxFailed: %v", err)
+	}
+}
+
+func TestPurgePublishedOutboxEvents(t *testing.T) {
+	s, mock := testOutboxStorage(t)
+	before := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM outbox WHERE id IN (SELECT id FROM outbox WHERE published_at < $1 ORDER BY id LIMIT $2)")).
+		WithArgs(before, 1000).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	response, err := s.PurgePublishedOutboxEvents(context.Background(), &models.PurgePublishedOutboxEventsRequest{PublishedBefore: before, Limit: 1000})
+	if err != nil {
+		t.Fatalf("PurgePublishedOutboxEvents: %v", err)
+	}
+	if response.Purged != 42 {
+		t.Errorf("purged %d, want 42", response.Purged)
+	}
+}
+
+// TestEnqueueEventRollsBackWithTheChange makes sure a change whose event
+// cannot be queued is not committed either.
+func TestEnqueueEventRollsBackWithTheChange(t *testing.T) {
+	s, mock, _ := testStorage(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO books").WillReturnRows(bookRows(&models.Book{BookId: testBookId, Title: "Dune", Version: 1}))
+	mock.ExpectExec("INSERT INTO book_revisions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO outbox").WillReturnError(context.DeadlineExceeded)
+	mock.ExpectRollback()
+
+	_, err := s.CreateBook(context.Background(), &models.CreateBookRequest{Title: "Dune", Author: "Frank Herbert", PublishedYear: 1965})
+	if err == nil {
+		t.Fatal("the book was created without its event")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// modelDocument patches a book as the JSON of models.Book, for callers
+// that name no document of their own.
+type modelDocument struct{}
+
+func (modelDocument) Marshal(book *models.Book) ([]byte, error) {
+	return json.Marshal(book)
+}
+
+func (modelDocument) Unmarshal(data []byte) (*models.Book, error) {
+	var book models.Book
+	if err := json.Unmarshal(data, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (modelDocument) IdField() string {
+	return "book_id"
+}
+
+// applyPatch applies an RFC 7396 merge patch or an RFC 6902 JSON patch to
+// current in the form of req.Document. A null or removed field comes back
+// as its zero value, which is how clients clear it.
+func applyPatch(current *models.Book, req *models.PatchBookRequest) (*models.Book, error) {
+	format := req.Document
+	if format == nil {
+		format = modelDocument{}
+	}
+	document, err := format.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var patchedDocument []byte
+	switch req.PatchType {
+	case models.MergePatchType:
+		if !json.Valid(req.Patch) {
+			return nil, apperrors.New(apperrors.Invalid, "merge patch is not valid JSON")
+		}
+		patchedDocument, err = jsonpatch.MergePatch(document, req.Patch)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.Invalid, err, "merge patch could not be applied")
+		}
+	case models.JSONPatchType:
+		operations, err := jsonpatch.DecodePatch(req.Patch)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.Invalid, err, "JSON patch is malformed")
+		}
+		patchedDocument, err = operations.Apply(document)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.Conflict, err, "JSON patch does not apply to the current book")
+		}
+	default:
+		return nil, apperrors.New(apperrors.Invalid, "unsupported patch type %q", req.PatchType)
+	}
+
+	patched, err := format.Unmarshal(patchedDocument)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.Validation, err, "patched book has fields of the wrong type")
+	}
+	var details []apperrors.Detail
+	if patched.BookId != current.BookId {
+		details = append(details, apperrors.Detail{Field: format.IdField(), Reason: "is read-only"})
+	}
+	if patched.Version != current.Version {
+		details = append(details, apperrors.Detail{Field: "version", Reason: "is read-only"})
+	}
+	if len(details) > 0 {
+		return nil, apperrors.WithDetails(apperrors.Validation, details, "book is invalid")
+	}
+	return patched, nil
+}
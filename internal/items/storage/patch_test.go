@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	v1 "github.com/ruziba3vich/boock/internal/items/http/dto/v1"
+	v2 "github.com/ruziba3vich/boock/internal/items/http/dto/v2"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+func TestApplyPatch(t *testing.T) {
+	current := &models.Book{BookId: testBookId, Title: "Dune", Author: "Frank Herbert", PublishedYear: 1965, Version: 3}
+	retitled := *current
+	retitled.Title = "Dune Messiah"
+
+	tests := []struct {
+		name      string
+		document  models.BookDocument
+		patchType string
+		patch     string
+		// want is the patched book, or nil if the patch fails with wantKind.
+		want     *models.Book
+		wantKind apperrors.Kind
+		// wantField is the field a read-only violation is reported on.
+		wantField string
+	}{
+		{"model merge patch", nil, models.MergePatchType, `{"title":"Dune Messiah"}`, &retitled, apperrors.Internal, ""},
+		{"v1 merge patch", v1.BookDocument{}, models.MergePatchType, `{"title":"Dune Messiah"}`, &retitled, apperrors.Internal, ""},
+		{"v1 test on the id", v1.BookDocument{}, models.JSONPatchType,
+			`[{"op":"test","path":"/book_id","value":"` + testBookId + `"},{"op":"replace","path":"/title","value":"Dune Messiah"}]`, &retitled, apperrors.Internal, ""},
+		{"v1 changed id", v1.BookDocument{}, models.MergePatchType, `{"book_id":"other"}`, nil, apperrors.Validation, "book_id"},
+		{"v2 merge patch", v2.BookDocument{}, models.MergePatchType, `{"title":"Dune Messiah"}`, &retitled, apperrors.Internal, ""},
+		{"v2 test on the id", v2.BookDocument{}, models.JSONPatchType,
+			`[{"op":"test","path":"/id","value":"` + testBookId + `"},{"op":"replace","path":"/title","value":"Dune Messiah"}]`, &retitled, apperrors.Internal, ""},
+		// v2 has no book_id, so a test on it fails like any missing path.
+		{"v2 test on the v1 id", v2.BookDocument{}, models.JSONPatchType,
+			`[{"op":"test","path":"/book_id","value":"` + testBookId + `"}]`, nil, apperrors.Conflict, ""},
+		{"v2 changed id", v2.BookDocument{}, models.MergePatchType, `{"id":"other"}`, nil, apperrors.Validation, "id"},
+		{"v2 removed id", v2.BookDocument{}, models.JSONPatchType, `[{"op":"remove","path":"/id"}]`, nil, apperrors.Validation, "id"},
+		{"v2 changed version", v2.BookDocument{}, models.MergePatchType, `{"version":4}`, nil, apperrors.Validation, "version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatch(current, &models.PatchBookRequest{
+				BookId:    testBookId,
+				PatchType: tt.patchType,
+				Patch:     []byte(tt.patch),
+				Document:  tt.document,
+			})
+			if tt.want != nil {
+				if err != nil {
+					t.Fatalf("applyPatch: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("got %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+			var appErr *apperrors.Error
+			if !errors.As(err, &appErr) || appErr.Kind != tt.wantKind {
+				t.Fatalf("got %v, want a %v error", err, tt.wantKind)
+			}
+			if tt.wantField != "" && (len(appErr.Details) != 1 || appErr.Details[0].Field != tt.wantField) {
+				t.Errorf("got details %+v, want %s to be read-only", appErr.Details, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/models"
+	"github.com/ruziba3vich/boock/internal/pkg/reqmeta"
+)
+
+// Columns book_revisions has on top of the book columns it shares with books.
+const (
+	revisionOperation  = "operation"
+	revisionChangedBy  = "changed_by"
+	revisionRecordedAt = "recorded_at"
+)
+
+// recordRevisionTx stores book, as it is after operation, as an immutable
+// revision. It must run in the transaction that made the change.
+func (s *Storage) recordRevisionTx(ctx context.Context, tx *sql.Tx, operation string, book *models.Book) error {
+	actor := reqmeta.From(ctx).Actor
+	query, args, err := s.queryBuilder.Insert(s.cfg.RevisionsTable).
+		Columns(s.cfg.BookId, s.cfg.Version, revisionOperation, s.cfg.Author, s.cfg.Title, s.cfg.PublishedYear, revisionChangedBy, revisionRecordedAt).
+		Values(book.BookId, book.Version, operation, book.Author, book.Title, book.PublishedYear, nullString(actor), book.UpdatedAt).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return nil
+}
+
+func (s *Storage) revisionColumns() []string {
+	return []string{s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublishedYear, s.cfg.Version, revisionRecordedAt, revisionOperation, revisionChangedBy}
+}
+
+func (s *Storage) scanRevision(row rowScanner) (*models.BookRevision, error) {
+	var (
+		book      models.Book
+		revision  models.BookRevision
+		changedBy sql.NullString
+	)
+	if err := row.Scan(&book.BookId, &book.Author, &book.Title, &book.PublishedYear, &book.Version, &book.UpdatedAt, &revision.Operation, &changedBy); err != nil {
+		return nil, err
+	}
+	revision.Revision = book.Version
+	revision.RecordedAt = book.UpdatedAt
+	revision.ChangedBy = changedBy.String
+	revision.Book = &book
+	return &revision, nil
+}
+
+func (s *Storage) GetBookHistory(ctx context.Context, req *models.GetBookHistoryRequest) (*models.GetBookHistoryResponse, error) {
+	if _, err := uuid.Parse(req.BookId); err != nil {
+		return nil, bookNotFound(req.BookId)
+	}
+	if req.Cursor != "" {
+		return nil, apperrors.New(apperrors.Invalid, "history only supports page based paging")
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := s.pageLimit(req.Limit)
+
+	countQuery, countArgs, err := s.queryBuilder.Select("COUNT(*)").
+		From(s.cfg.RevisionsTable).
+		Where(sq.Eq{s.cfg.BookId: req.BookId}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	var total int64
+	if err := s.postgres.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	if total == 0 {
+		return nil, bookNotFound(req.BookId)
+	}
+
+	query, args, err := s.queryBuilder.Select(s.revisionColumns()...).
+		From(s.cfg.RevisionsTable).
+		Where(sq.Eq{s.cfg.BookId: req.BookId}).
+		OrderBy(s.cfg.Version + " DESC").
+		Limit(uint64(limit)).
+		Offset(uint64((page - 1) * limit)).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	revisions := []*models.BookRevision{}
+	for rows.Next() {
+		revision, err := s.scanRevision(rows)
+		if err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	return &models.GetBookHistoryResponse{
+		Revisions: revisions,
+		Pagination: &models.Pagination{
+			Total:   total,
+			Page:    page,
+			Limit:   limit,
+			HasNext: int64(page*limit) < total,
+		},
+	}, nil
+}
+
+// previousRevisionTx returns book as it was before its latest change, or nil
+// when that revision is not recorded.
+func (s *Storage) previousRevisionTx(ctx context.Context, tx *sql.Tx, book *models.Book) (*models.Book, error) {
+	query, args, err := s.queryBuilder.Select(s.revisionColumns()...).
+		From(s.cfg.RevisionsTable).
+		Where(sq.Eq{s.cfg.BookId: book.BookId, s.cfg.Version: book.Version - 1}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	revision, err := s.scanRevision(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	if revision.Operation == models.RevisionDelete {
+		deletedAt := revision.RecordedAt
+		revision.Book.DeletedAt = &deletedAt
+	}
+	return revision.Book, nil
+}
+
+// bookAsOf rebuilds a book from the last revision recorded at or before
+// asOf. A book that did not exist yet, or was in the trash, is not found.
+func (s *Storage) bookAsOf(ctx context.Context, bookId string, asOf time.Time) (*models.Book, error) {
+	query, args, err := s.queryBuilder.Select(s.revisionColumns()...).
+		From(s.cfg.RevisionsTable).
+		Where(sq.Eq{s.cfg.BookId: bookId}).
+		Where(sq.LtOrEq{revisionRecordedAt: asOf}).
+		OrderBy(s.cfg.Version + " DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+
+	revision, err := s.scanRevision(s.postgres.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.New(apperrors.NotFound, "book %s did not exist at %s", bookId, asOf.Format(time.RFC3339))
+	} else if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	if revision.Operation == models.RevisionDelete {
+		return nil, apperrors.New(apperrors.NotFound, "book %s was deleted at %s", bookId, asOf.Format(time.RFC3339))
+	}
+	return revision.Book, nil
+}
+
+// RevertBook writes the content of an earlier revision as a new version of
+// the book. History is never rewritten.
+func (s *Storage) RevertBook(ctx context.Context, req *models.RevertBookRequest) (*models.Book, error) {
+	if _, err := uuid.Parse(req.BookId); err != nil {
+		return nil, bookNotFound(req.BookId)
+	}
+
+	tx, err := s.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Println("Error while starting a transaction")
+		return nil, dbError(err)
+	}
+	defer tx.Rollback()
+
+	query, args, err := s.queryBuilder.Select(s.revisionColumns()...).
+		From(s.cfg.RevisionsTable).
+		Where(sq.Eq{s.cfg.BookId: req.BookId, s.cfg.Version: req.Revision}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	revision, err := s.scanRevision(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.New(apperrors.NotFound, "revision %d of book %s not found", req.Revision, req.BookId)
+	} else if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	if revision.Operation == models.RevisionDelete {
+		return nil, apperrors.New(apperrors.Conflict, "revision %d of book %s is a deletion and cannot be reverted to", req.Revision, req.BookId)
+	}
+
+	target := revision.Book
+	target.Version = req.ExpectedVersion
+	if err := s.checker.CheckBook(target); err != nil {
+		return nil, err
+	}
+
+	updatedBook, err := s.replaceBookTx(ctx, tx, target)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordChangeTx(ctx, tx, models.RevisionRevert, updatedBook); err != nil {
+		return nil, err
+	}
+	before, err := s.previousRevisionTx(ctx, tx, updatedBook)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Println("Error while commiting transaction :", err.Error())
+		return nil, dbError(err)
+	}
+	s.committed(ctx, models.RevisionRevert, before, updatedBook)
+	return updatedBook, nil
+}
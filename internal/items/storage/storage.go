@@ -3,7 +3,11 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -11,6 +15,13 @@ import (
 	"github.com/ruziba3vich/boock/internal/items/redisservice"
 	"github.com/ruziba3vich/boock/internal/items/repository"
 	"github.com/ruziba3vich/boock/internal/models"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	searchLanguage   = "simple"
+	headlineOptions  = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	defaultPageLimit = 10
 )
 
 type Storage struct {
@@ -18,214 +29,528 @@ type Storage struct {
 	postgres     *sql.DB
 	queryBuilder sq.StatementBuilderType
 	cfg          *config.Config
+	checker      repository.IBookChecker
+	observer     repository.IBookObserver
 	logger       *log.Logger
+	// catalogUnsynced is set while Redis may lack this process's last change
+	// to the catalog.
+	catalogUnsynced atomic.Bool
+	// flight coalesces concurrent refills of one cached book.
+	flight singleflight.Group
 }
 
-func New(redis *redisservice.RedisService, postgres *sql.DB, queryBuilder sq.StatementBuilderType, cfg *config.Config, logger *log.Logger) repository.IBookRepo {
+// New returns the book storage. checker vets the books it works out from a
+// patch or a revision, and observer is told about every committed change.
+func New(redis *redisservice.RedisService, postgres *sql.DB, queryBuilder sq.StatementBuilderType, cfg *config.Config, checker repository.IBookChecker, observer repository.IBookObserver, logger *log.Logger) repository.IBookRepo {
 	return &Storage{
 		redis:        redis,
 		postgres:     postgres,
 		queryBuilder: queryBuilder,
 		cfg:          cfg,
+		checker:      checker,
+		observer:     observer,
 		logger:       logger,
 	}
 }
 
+func (s *Storage) pageLimit(limit int) int {
+	return clampPageLimit(limit, s.cfg.Server.MaxPageSize)
+}
+
+func clampPageLimit(limit, maxPageSize int) int {
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return limit
+}
+
 func (s *Storage) CreateBook(ctx context.Context, req *models.CreateBookRequest) (*models.Book, error) {
 	tx, err := s.postgres.BeginTx(ctx, nil)
 	if err != nil {
 		s.logger.Println("Error while starting a transaction")
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer tx.Rollback()
 
 	bookId := uuid.New().String()
 	query, args, err := s.queryBuilder.Insert(s.cfg.TableName).
-		Columns(s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublisherYear).
-		Values(bookId, req.Author, req.Title, req.PublisherYear).
+		Columns(s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublishedYear).
+		Values(bookId, req.Author, req.Title, req.PublishedYear).
+		Suffix("RETURNING " + strings.Join(s.bookColumns(), ", ")).
 		ToSql()
 	if err != nil {
 		s.logger.Println(err)
 		return nil, err
 	}
-	rows, err := tx.ExecContext(ctx, query, args...)
+	book, err := s.scanBook(tx.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		s.logger.Println(err)
-		return nil, err
+		return nil, dbError(err)
 	}
-	rowsAffected, err := rows.RowsAffected()
-	if err != nil {
-		s.logger.Println(err)
+	if err := s.recordChangeTx(ctx, tx, models.RevisionCreate, book); err != nil {
 		return nil, err
 	}
-	if rowsAffected == 0 {
-		return nil, sql.ErrNoRows
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Println("Error while commiting transaction :", err.Error())
+		return nil, dbError(err)
+	}
+	s.committed(ctx, models.RevisionCreate, nil, book)
+	return book, nil
+}
+
+func (s *Storage) UpdateBook(ctx context.Context, req *models.UpdateBookRequest) (*models.Book, error) {
+	if _, err := uuid.Parse(req.BookId); err != nil {
+		return nil, bookNotFound(req.BookId)
+	}
+
+	tx, err := s.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Println("Error while starting a transaction")
+		return nil, dbError(err)
 	}
+	defer tx.Rollback()
 
-	book := models.Book{
-		BookId:        string(bookId),
+	updatedBook, err := s.replaceBookTx(ctx, tx, &models.Book{
+		BookId:        req.BookId,
 		Author:        req.Author,
 		Title:         req.Title,
-		PublisherYear: req.PublisherYear,
+		PublishedYear: req.PublishedYear,
+		Version:       req.ExpectedVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordChangeTx(ctx, tx, models.RevisionUpdate, updatedBook); err != nil {
+		return nil, err
 	}
-	result, err := s.redis.StoreBookInRedis(ctx, &book)
+	before, err := s.previousRevisionTx(ctx, tx, updatedBook)
 	if err != nil {
 		return nil, err
 	}
+
 	if err := tx.Commit(); err != nil {
 		s.logger.Println("Error while commiting transaction :", err.Error())
+		return nil, dbError(err)
 	}
-	return result, nil
+	s.committed(ctx, models.RevisionUpdate, before, updatedBook)
+	return updatedBook, nil
 }
 
-func (s *Storage) UpdateBook(ctx context.Context, req *models.UpdateBookRequest) (*models.Book, error) {
+func (s *Storage) PatchBook(ctx context.Context, req *models.PatchBookRequest) (*models.Book, error) {
+	if _, err := uuid.Parse(req.BookId); err != nil {
+		return nil, bookNotFound(req.BookId)
+	}
+
 	tx, err := s.postgres.BeginTx(ctx, nil)
 	if err != nil {
 		s.logger.Println("Error while starting a transaction")
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer tx.Rollback()
 
-	queryBuilder := s.queryBuilder.Update(s.cfg.TableName)
-
-	if len(req.Author) > 0 {
-		queryBuilder = queryBuilder.Set(s.cfg.Author, req.Author)
+	query, args, err := s.queryBuilder.Select(s.bookColumns()...).
+		From(s.cfg.TableName).
+		Where(sq.Eq{s.cfg.BookId: req.BookId}).
+		Where(s.live()).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
 	}
-	if len(req.Title) > 0 {
-		queryBuilder = queryBuilder.Set(s.cfg.Title, req.Title)
+	current, err := s.scanBook(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, bookNotFound(req.BookId)
+	} else if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
 	}
-	if req.PublisherYear != 0 {
-		queryBuilder = queryBuilder.Set(s.cfg.PublisherYear, req.PublisherYear)
+	if req.ExpectedVersion != 0 && req.ExpectedVersion != current.Version {
+		return nil, versionMismatch(req.BookId, current.Version)
 	}
 
-	queryBuilder = queryBuilder.Where(sq.Eq{s.cfg.BookId: req.BookId})
-
-	query, args, err := queryBuilder.ToSql()
+	patched, err := applyPatch(current, req)
 	if err != nil {
-		s.logger.Println(err)
 		return nil, err
 	}
-
-	result, err := s.postgres.ExecContext(ctx, query, args...)
-	if err != nil {
-		s.logger.Println(err)
+	if err := s.checker.CheckBook(patched); err != nil {
 		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	updatedBook, err := s.replaceBookTx(ctx, tx, patched)
 	if err != nil {
-		s.logger.Println(err)
 		return nil, err
 	}
-	if rowsAffected == 0 {
-		return nil, sql.ErrNoRows
+	if err := s.recordChangeTx(ctx, tx, models.RevisionUpdate, updatedBook); err != nil {
+		return nil, err
 	}
 
-	updatedBook, err := s.GetBookById(ctx, &models.GetBookByIdRequest{BookId: req.BookId})
-	if err != nil {
-		s.logger.Println(err)
-		return nil, err
+	if err := tx.Commit(); err != nil {
+		s.logger.Println("Error while commiting transaction :", err.Error())
+		return nil, dbError(err)
 	}
+	s.committed(ctx, models.RevisionUpdate, current, updatedBook)
+	return updatedBook, nil
+}
 
-	redisBook, err := s.redis.StoreBookInRedis(ctx, updatedBook)
+// replaceBookTx overwrites every editable column of book.BookId, bumps its
+// version and returns the row as stored. A non-zero book.Version must match
+// the stored one.
+func (s *Storage) replaceBookTx(ctx context.Context, tx *sql.Tx, book *models.Book) (*models.Book, error) {
+	where := sq.And{sq.Eq{s.cfg.BookId: book.BookId}, s.live()}
+	if book.Version != 0 {
+		where = append(where, sq.Eq{s.cfg.Version: book.Version})
+	}
+
+	query, args, err := s.queryBuilder.Update(s.cfg.TableName).
+		Set(s.cfg.Author, book.Author).
+		Set(s.cfg.Title, book.Title).
+		Set(s.cfg.PublishedYear, book.PublishedYear).
+		Set(s.cfg.Version, sq.Expr(s.cfg.Version+" + 1")).
+		Set(s.cfg.UpdatedAt, sq.Expr("now()")).
+		Where(where).
+		Suffix("RETURNING " + strings.Join(s.bookColumns(), ", ")).
+		ToSql()
 	if err != nil {
+		s.logger.Println(err)
 		return nil, err
 	}
-	if err := tx.Commit(); err != nil {
-		s.logger.Println("Error while commiting transaction :", err.Error())
+
+	updatedBook, err := s.scanBook(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, s.missingBookError(ctx, tx, book.BookId)
+	} else if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
 	}
-	return redisBook, nil
+	return updatedBook, nil
 }
 
 func (s *Storage) GetBookById(ctx context.Context, req *models.GetBookByIdRequest) (*models.Book, error) {
-	redisBook, _ := s.redis.GetBookFromRedis(ctx, req.BookId)
+	if _, err := uuid.Parse(req.BookId); err != nil {
+		return nil, bookNotFound(req.BookId)
+	}
+	if !req.AsOf.IsZero() {
+		return s.bookAsOf(ctx, req.BookId, req.AsOf)
+	}
+	redisBook, stale, _ := s.redis.GetBook(ctx, req.BookId)
+	countCache(queryBook, redisBook != nil)
 	if redisBook != nil {
+		if stale {
+			s.refreshBook(ctx, req.BookId)
+		}
 		return redisBook, nil
 	}
-	query, args, err := s.queryBuilder.Select(s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublisherYear).
+	return s.loadBook(ctx, req.BookId)
+}
+
+// bookFromPostgres reads a live book, bypassing the cache.
+func (s *Storage) bookFromPostgres(ctx context.Context, bookId string) (*models.Book, error) {
+	query, args, err := s.queryBuilder.Select(s.bookColumns()...).
 		From(s.cfg.TableName).
-		Where(sq.Eq{s.cfg.BookId: req.BookId}).
+		Where(sq.Eq{s.cfg.BookId: bookId}).
+		Where(s.live()).
 		ToSql()
 	if err != nil {
 		s.logger.Println(err)
 		return nil, err
 	}
-	row := s.postgres.QueryRowContext(ctx, query, args...)
-	var book models.Book
-	if err := row.Scan(&book.BookId, &book.Author, &book.Title, &book.PublisherYear); err != nil {
+	book, err := s.scanBook(s.postgres.QueryRowContext(ctx, query, args...))
+	if err != nil {
 		s.logger.Println(err)
-		return nil, err
+		if err == sql.ErrNoRows {
+			return nil, bookNotFound(bookId)
+		}
+		return nil, dbError(err)
 	}
-	return &book, nil
+	return book, nil
 }
 
 func (s *Storage) GetAllBooks(ctx context.Context, req *models.GetAllBooksRequest) (*models.GetSeveralResponse, error) {
-	query, args, err := s.queryBuilder.Select(s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublisherYear).
-		From(s.cfg.TableName).
-		ToSql()
+	where, order, err := s.parseListQuery(req.Filter, req.Sort)
 	if err != nil {
 		s.logger.Println(err)
 		return nil, err
 	}
-	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	conditions := sq.And{s.live()}
+	if where != nil {
+		conditions = append(conditions, where)
+	}
+
+	// The parsed filter is the normal form of the filter as written.
+	clause, args, err := conditions.ToSql()
 	if err != nil {
 		s.logger.Println(err)
 		return nil, err
 	}
-	defer rows.Close()
+	query := map[string]interface{}{"where": clause, "args": args, "order": order.String(), "paging": s.normalPaging(req.Paging)}
+	return s.cachedList(ctx, queryAll, query, nil, func() (*models.GetSeveralResponse, error) {
+		return s.listBooks(ctx, conditions, order, req.Paging)
+	})
+}
 
-	var books []*models.Book
-	for rows.Next() {
-		var book models.Book
-		if err := rows.Scan(&book.BookId, &book.Author, &book.Title, &book.PublisherYear); err != nil {
-			s.logger.Println(err)
+func (s *Storage) GetBooksByAuthor(ctx context.Context, req *models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error) {
+	query := map[string]interface{}{"author": req.Author, "paging": s.normalPaging(req.Paging)}
+	// Tagged with its author, so a book joining or leaving it drops it.
+	return s.cachedList(ctx, queryAuthor, query, []string{"author:" + req.Author}, func() (*models.GetSeveralResponse, error) {
+		return s.listBooks(ctx, sq.And{s.live(), sq.Eq{s.cfg.Author: req.Author}}, s.defaultOrdering(), req.Paging)
+	})
+}
+
+func (s *Storage) GetBooksByName(ctx context.Context, req *models.GetBooksByNameRequest) (*models.GetSeveralResponse, error) {
+	// ILIKE ignores case.
+	query := map[string]interface{}{"name": strings.ToLower(req.BookName), "paging": s.normalPaging(req.Paging)}
+	return s.cachedList(ctx, queryTitle, query, nil, func() (*models.GetSeveralResponse, error) {
+		return s.listBooks(ctx, sq.And{s.live(), sq.ILike{s.cfg.Title: "%" + req.BookName + "%"}}, s.defaultOrdering(), req.Paging)
+	})
+}
+
+func (s *Storage) cachedList(ctx context.Context, queryType string, query interface{}, tags []string, load func() (*models.GetSeveralResponse, error)) (*models.GetSeveralResponse, error) {
+	var response *models.GetSeveralResponse
+	err := s.cacheAside(ctx, queryType, query, &response, func() ([]string, error) {
+		var err error
+		if response, err = load(); err != nil {
 			return nil, err
 		}
-		books = append(books, &book)
+		return append(tags, bookTags(response.Books)...), nil
+	})
+	return response, err
+}
+
+// normalPaging is paging as listBooks reads it.
+func (s *Storage) normalPaging(paging models.Paging) models.Paging {
+	paging.Limit = s.pageLimit(paging.Limit)
+	if paging.Cursor != "" || paging.Page < 1 {
+		paging.Page = 1
 	}
-	if err := rows.Err(); err != nil {
+	return paging
+}
+
+func (s *Storage) DeleteBookById(ctx context.Context, req *models.DeleteBookByIdRequest) error {
+	tx, err := s.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Println("Error starting transaction:", err)
+		return dbError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := uuid.Parse(req.BookId); err != nil {
+		return bookNotFound(req.BookId)
+	}
+
+	where := sq.And{sq.Eq{s.cfg.BookId: req.BookId}, s.live()}
+	if req.ExpectedVersion != 0 {
+		where = append(where, sq.Eq{s.cfg.Version: req.ExpectedVersion})
+	}
+
+	query, args, err := s.queryBuilder.Update(s.cfg.TableName).
+		Set(s.cfg.DeletedAt, sq.Expr("now()")).
+		Set(s.cfg.UpdatedAt, sq.Expr("now()")).
+		Set(s.cfg.Version, sq.Expr(s.cfg.Version+" + 1")).
+		Where(where).
+		Suffix("RETURNING " + strings.Join(s.bookColumns(), ", ")).
+		ToSql()
+	if err != nil {
+		s.logger.Println("Error building SQL query:", err)
+		return err
+	}
+
+	deletedBook, err := s.scanBook(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		s.logger.Println("No rows affected for book", req.BookId)
+		return s.missingBookError(ctx, tx, req.BookId)
+	} else if err != nil {
+		s.logger.Println("Error executing SQL query:", err)
+		return dbError(err)
+	}
+	if err := s.recordChangeTx(ctx, tx, models.RevisionDelete, deletedBook); err != nil {
+		return err
+	}
+	before, err := s.previousRevisionTx(ctx, tx, deletedBook)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Println("Error committing transaction:", err)
+		return dbError(err)
+	}
+	s.committed(ctx, models.RevisionDelete, before, deletedBook)
+
+	return nil
+}
+
+func (s *Storage) ListTrash(ctx context.Context, req *models.ListTrashRequest) (*models.GetSeveralResponse, error) {
+	return s.listBooks(ctx, sq.NotEq{s.cfg.DeletedAt: nil}, s.defaultOrdering(), req.Paging)
+}
+
+func (s *Storage) RestoreBook(ctx context.Context, req *models.RestoreBookRequest) (*models.Book, error) {
+	if _, err := uuid.Parse(req.BookId); err != nil {
+		return nil, bookNotFound(req.BookId)
+	}
+
+	tx, err := s.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		s.logger.Println("Error while starting a transaction")
+		return nil, dbError(err)
+	}
+	defer tx.Rollback()
+
+	query, args, err := s.queryBuilder.Update(s.cfg.TableName).
+		Set(s.cfg.DeletedAt, nil).
+		Set(s.cfg.UpdatedAt, sq.Expr("now()")).
+		Set(s.cfg.Version, sq.Expr(s.cfg.Version+" + 1")).
+		Where(sq.Eq{s.cfg.BookId: req.BookId}).
+		Where(sq.NotEq{s.cfg.DeletedAt: nil}).
+		Suffix("RETURNING " + strings.Join(s.bookColumns(), ", ")).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+
+	book, err := s.scanBook(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, s.notInTrashError(ctx, tx, req.BookId)
+	} else if err != nil {
 		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	if err := s.recordChangeTx(ctx, tx, models.RevisionRestore, book); err != nil {
+		return nil, err
+	}
+	before, err := s.previousRevisionTx(ctx, tx, book)
+	if err != nil {
 		return nil, err
 	}
-	return &models.GetSeveralResponse{Books: books}, nil
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Println("Error while commiting transaction :", err.Error())
+		return nil, dbError(err)
+	}
+	s.committed(ctx, models.RevisionRestore, before, book)
+	return book, nil
 }
 
-func (s *Storage) GetBooksByAuthor(ctx context.Context, req *models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error) {
-	query, args, err := s.queryBuilder.Select(s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublisherYear).
-		From(s.cfg.TableName).
-		Where(sq.Eq{s.cfg.Author: req.Author}).
+// PurgeDeletedBooks permanently removes books that went to the trash before
+// req.DeletedBefore.
+func (s *Storage) PurgeDeletedBooks(ctx context.Context, req *models.PurgeDeletedBooksRequest) (*models.PurgeDeletedBooksResponse, error) {
+	query, args, err := s.queryBuilder.Delete(s.cfg.TableName).
+		Where(sq.Lt{s.cfg.DeletedAt: req.DeletedBefore}).
 		ToSql()
 	if err != nil {
 		s.logger.Println(err)
 		return nil, err
 	}
-	rows, err := s.postgres.QueryContext(ctx, query, args...)
+
+	res, err := s.postgres.ExecContext(ctx, query, args...)
 	if err != nil {
 		s.logger.Println(err)
-		return nil, err
+		return nil, dbError(err)
 	}
-	defer rows.Close()
+	purged, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	return &models.PurgeDeletedBooksResponse{Purged: purged}, nil
+}
 
-	var books []*models.Book
-	for rows.Next() {
-		var book models.Book
-		if err := rows.Scan(&book.BookId, &book.Author, &book.Title, &book.PublisherYear); err != nil {
-			s.logger.Println(err)
-			return nil, err
+// GetCatalogVersion answers from Redis, unless this process failed to record
+// its last change there; then the stored time may be too old, and would turn
+// away clients with a 304 they should not get.
+func (s *Storage) GetCatalogVersion(ctx context.Context) (*models.CatalogVersion, error) {
+	var modifiedAt time.Time
+	if !s.catalogUnsynced.Load() {
+		var err error
+		if modifiedAt, err = s.redis.GetCatalogModified(ctx); err != nil {
+			s.logger.Println("Error reading catalog version from Redis:", err)
 		}
-		books = append(books, &book)
 	}
-	if err := rows.Err(); err != nil {
+	if !modifiedAt.IsZero() {
+		return &models.CatalogVersion{ModifiedAt: modifiedAt}, nil
+	}
+
+	query, args, err := s.queryBuilder.Select(fmt.Sprintf("COALESCE(MAX(%s), 'epoch')", s.cfg.UpdatedAt)).
+		From(s.cfg.TableName).
+		ToSql()
+	if err != nil {
 		s.logger.Println(err)
 		return nil, err
 	}
-	return &models.GetSeveralResponse{Books: books}, nil
+	if err := s.postgres.QueryRowContext(ctx, query, args...).Scan(&modifiedAt); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	if err := s.redis.SetCatalogModified(ctx, modifiedAt); err != nil {
+		s.logger.Println("Error storing catalog version in Redis:", err)
+	} else {
+		s.catalogUnsynced.Store(false)
+	}
+	return &models.CatalogVersion{ModifiedAt: modifiedAt}, nil
 }
 
-func (s *Storage) GetBooksByName(ctx context.Context, req *models.GetBooksByNameRequest) (*models.GetSeveralResponse, error) {
-	query, args, err := s.queryBuilder.Select(s.cfg.BookId, s.cfg.Author, s.cfg.Title, s.cfg.PublisherYear).
+// committed does what a mutation calls for once it is committed: the catalog
+// version moves, the cache forgets the book and the observer is told. The
+// change stands even if the caller has gone away, so none of it may be cut
+// short by ctx being canceled.
+func (s *Storage) committed(ctx context.Context, operation string, before, after *models.Book) {
+	ctx = context.WithoutCancel(ctx)
+	s.touchCatalog(ctx, after.UpdatedAt)
+	s.invalidate(ctx, operation, before, after)
+	s.observer.BookChanged(ctx, before, after)
+}
+
+// touchCatalog records a committed change, whose row the database stamped
+// with updatedAt, so both sources of the catalog version use its clock. It
+// runs after the commit; until a later write to Redis succeeds, this process
+// reads the catalog version from Postgres.
+func (s *Storage) touchCatalog(ctx context.Context, updatedAt time.Time) {
+	if err := s.redis.SetCatalogModified(ctx, updatedAt); err != nil {
+		s.logger.Println("Error updating catalog version in Redis:", err)
+		s.catalogUnsynced.Store(true)
+		return
+	}
+	s.catalogUnsynced.Store(false)
+}
+
+func (s *Storage) SearchBooks(ctx context.Context, req *models.SearchBooksRequest) (*models.SearchBooksResponse, error) {
+	// websearch_to_tsquery with the simple configuration ignores case and
+	// spacing.
+	query := map[string]interface{}{"search": strings.Join(strings.Fields(strings.ToLower(req.Search)), " "), "limit": s.pageLimit(req.Limit)}
+	var response *models.SearchBooksResponse
+	err := s.cacheAside(ctx, querySearch, query, &response, func() ([]string, error) {
+		var err error
+		if response, err = s.searchBooks(ctx, req); err != nil {
+			return nil, err
+		}
+		books := make([]*models.Book, len(response.Results))
+		for i, result := range response.Results {
+			books[i] = result.Book
+		}
+		return bookTags(books), nil
+	})
+	return response, err
+}
+
+func (s *Storage) searchBooks(ctx context.Context, req *models.SearchBooksRequest) (*models.SearchBooksResponse, error) {
+	limit := s.pageLimit(req.Limit)
+
+	query, args, err := s.queryBuilder.Select(s.bookColumns()...).
+		Column(fmt.Sprintf("ts_rank(%s, query) AS rank", s.cfg.SearchVector)).
+		Column(fmt.Sprintf("ts_headline('%s', %s, query, '%s')", searchLanguage, s.cfg.Title, headlineOptions)).
+		Column(fmt.Sprintf("ts_headline('%s', %s, query, '%s')", searchLanguage, s.cfg.Author, headlineOptions)).
 		From(s.cfg.TableName).
-		Where(sq.Like{s.cfg.Title: "%" + s.cfg.Title + "%"}).
+		CrossJoin(fmt.Sprintf("websearch_to_tsquery('%s', ?) AS query", searchLanguage), req.Search).
+		Where(fmt.Sprintf("%s @@ query", s.cfg.SearchVector)).
+		Where(s.live()).
+		OrderBy("rank DESC", s.cfg.BookId).
+		Limit(uint64(limit)).
 		ToSql()
 	if err != nil {
 		s.logger.Println(err)
@@ -234,69 +559,27 @@ func (s *Storage) GetBooksByName(ctx context.Context, req *models.GetBooksByName
 	rows, err := s.postgres.QueryContext(ctx, query, args...)
 	if err != nil {
 		s.logger.Println(err)
-		return nil, err
+		return nil, dbError(err)
 	}
 	defer rows.Close()
 
-	var books []*models.Book
+	results := []*models.SearchResult{}
 	for rows.Next() {
+		var result models.SearchResult
 		var book models.Book
-		if err := rows.Scan(&book.BookId, &book.Author, &book.Title, &book.PublisherYear); err != nil {
+		fields := append(s.bookFields(&book), &result.Rank, &result.Highlights.Title, &result.Highlights.Author)
+		if err := rows.Scan(fields...); err != nil {
 			s.logger.Println(err)
-			return nil, err
+			return nil, dbError(err)
 		}
-		books = append(books, &book)
+		result.Book = &book
+		results = append(results, &result)
 	}
 	if err := rows.Err(); err != nil {
 		s.logger.Println(err)
-		return nil, err
-	}
-	return &models.GetSeveralResponse{Books: books}, nil
-}
-
-func (s *Storage) DeleteBookById(ctx context.Context, req *models.DeleteBookByIdRequest) error {
-	tx, err := s.postgres.BeginTx(ctx, nil)
-	if err != nil {
-		s.logger.Println("Error starting transaction:", err)
-		return err
-	}
-	defer tx.Rollback()
-
-	query, args, err := s.queryBuilder.Delete(s.cfg.TableName).
-		Where(sq.Eq{s.cfg.BookId: req.BookId}).
-		ToSql()
-	if err != nil {
-		s.logger.Println("Error building SQL query:", err)
-		return err
-	}
-
-	res, err := tx.ExecContext(ctx, query, args...)
-	if err != nil {
-		s.logger.Println("Error executing SQL query:", err)
-		return err
-	}
-
-	ra, err := res.RowsAffected()
-	if ra == 0 || err != nil {
-		s.logger.Println("No rows affected:", err)
-		return err
-	}
-
-	if err := s.redis.DeleteBookFromRedis(ctx, "book:"+req.BookId); err != nil {
-		s.logger.Println("Error deleting book from Redis:", err)
-		return err
-	}
-
-	if err := tx.Commit(); err != nil {
-		s.logger.Println("Error committing transaction:", err)
-		return err
+		return nil, dbError(err)
 	}
-
-	return nil
-}
-
-func (s *Storage) SearchBooks(ctx context.Context, req *models.SearchBooksRequest) (*models.GetSeveralResponse, error) {
-	return nil, nil
+	return &models.SearchBooksResponse{Results: results}, nil
 }
 
 /*
@@ -306,6 +589,6 @@ func (s *Storage) SearchBooks(ctx context.Context, req *models.SearchBooksReques
 	GetAllBooks(*models.GetAllBooksRequest) (*models.GetSeveralResponse, error)
 	GetBooksByAuthor(*models.GetBooksByAuthorRequest) (*models.GetSeveralResponse, error)
 	GetBooksByName(*models.GetBooksByNameRequest) (*models.GetSeveralResponse, error)
-	SearchBooks(*models.SearchBooksRequest) (*models.GetSeveralResponse, error)
+	SearchBooks(*models.SearchBooksRequest) (*models.SearchBooksResponse, error)
 	DeleteBookById(context.Context,*models.DeleteBookByIdRequest) error
 */
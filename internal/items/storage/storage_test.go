@@ -0,0 +1,302 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/redisservice"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+const testBookId = "8d1c7f3e-2b4a-4f6e-9c1d-3a5b7e9f0a12"
+
+type (
+	acceptAll   struct{}
+	nopObserver struct{}
+)
+
+func (acceptAll) CheckBook(*models.Book) error {
+	return nil
+}
+
+func (nopObserver) BookChanged(context.Context, *models.Book, *models.Book) {}
+
+func testConfig() *config.Config {
+	cfg := &config.Config{
+		TableName:       "books",
+		RevisionsTable:  "book_revisions",
+		AuditTable:      "audit_log",
+		OutboxTable:     "outbox",
+		WebhooksTable:   "webhooks",
+		DeliveriesTable: "webhook_deliveries",
+		BookId:          "book_id",
+		Title:           "title",
+		Author:          "author",
+		PublishedYear:   "published_year",
+		Version:         "version",
+		UpdatedAt:       "updated_at",
+		DeletedAt:       "deleted_at",
+	}
+	cfg.Server.MaxPageSize = 100
+	cfg.Server.CursorSecret = "secret"
+	cfg.Redis = config.RedisConfig{
+		KeyPrefix:        "test",
+		BookTTL:          time.Hour,
+		StaleGrace:       time.Minute,
+		LockTTL:          time.Second,
+		LockWait:         100 * time.Millisecond,
+		QueryTTL:         time.Minute,
+		LocalSize:        100,
+		LocalTTL:         time.Minute,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Second,
+	}
+	return cfg
+}
+
+// testStorage is a Storage on sqlmock and miniredis. Any SQL the test did
+// not expect fails it, so a read served from the cache shows as one that
+// expects nothing.
+func testStorage(t *testing.T) (*Storage, sqlmock.Sqlmock, *miniredis.Miniredis) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := testConfig()
+	logger := log.New(io.Discard, "", 0)
+	s := New(redisservice.New(client, cfg.Redis, logger), db, sq.StatementBuilder.PlaceholderFormat(sq.Dollar), cfg, acceptAll{}, nopObserver{}, logger)
+	return s.(*Storage), mock, mr
+}
+
+func bookRows(books ...*models.Book) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"book_id", "author", "title", "published_year", "version", "updated_at", "deleted_at"})
+	for _, book := range books {
+		var deletedAt driver.Value
+		if book.DeletedAt != nil {
+			deletedAt = *book.DeletedAt
+		}
+		rows.AddRow(book.BookId, book.Author, book.Title, book.PublishedYear, book.Version, book.UpdatedAt, deletedAt)
+	}
+	return rows
+}
+
+func revisionRows(book *models.Book, operation string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"book_id", "author", "title", "published_year", "version", "recorded_at", "operation", "changed_by"}).
+		AddRow(book.BookId, book.Author, book.Title, book.PublishedYear, book.Version, book.UpdatedAt, operation, nil)
+}
+
+// expectChange expects what every write records in its transaction: the
+// revision, the outbox event and the webhook deliveries.
+func expectChange(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("INSERT INTO book_revisions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO webhook_deliveries").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestWritesKeepTheCacheCurrent(t *testing.T) {
+	ctx := context.Background()
+	s, mock, mr := testStorage(t)
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	v1 := &models.Book{BookId: testBookId, Title: "Dune", Author: "Frank Herbert", PublishedYear: 1965, Version: 1, UpdatedAt: at}
+	v2 := &models.Book{BookId: testBookId, Title: "Dune Messiah", Author: "Frank Herbert", PublishedYear: 1969, Version: 2, UpdatedAt: at.Add(time.Minute)}
+	deletedAt := at.Add(2 * time.Minute)
+	v3 := *v2
+	v3.Version, v3.UpdatedAt, v3.DeletedAt = 3, deletedAt, &deletedAt
+
+	read := func(want *models.Book) {
+		t.Helper()
+		book, err := s.GetBookById(ctx, &models.GetBookByIdRequest{BookId: testBookId})
+		if err != nil {
+			t.Fatalf("GetBookById: %v", err)
+		}
+		if *book != *want {
+			t.Fatalf("got %+v, want %+v", book, want)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	catalog := func(want time.Time) {
+		t.Helper()
+		got, err := s.redis.GetCatalogModified(ctx)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("catalog modified at %s, %v; want %s", got, err, want)
+		}
+	}
+
+	// Create.
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO books").WillReturnRows(bookRows(v1))
+	expectChange(mock)
+	mock.ExpectCommit()
+	if _, err := s.CreateBook(ctx, &models.CreateBookRequest{Title: v1.Title, Author: v1.Author, PublishedYear: v1.PublishedYear}); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	catalog(v1.UpdatedAt)
+
+	// The first read misses and fills the cache; the second is a hit.
+	mock.ExpectQuery("SELECT (.+) FROM books WHERE").WillReturnRows(bookRows(v1))
+	read(v1)
+	read(v1)
+
+	// Update.
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE books SET").WillReturnRows(bookRows(v2))
+	expectChange(mock)
+	mock.ExpectQuery("SELECT (.+) FROM book_revisions").WillReturnRows(revisionRows(v1, models.RevisionCreate))
+	mock.ExpectCommit()
+	if _, err := s.UpdateBook(ctx, &models.UpdateBookRequest{BookId: testBookId, Title: v2.Title, Author: v2.Author, PublishedYear: v2.PublishedYear, ExpectedVersion: 1}); err != nil {
+		t.Fatalf("UpdateBook: %v", err)
+	}
+	catalog(v2.UpdatedAt)
+	if !mr.Exists("test:book:" + testBookId) {
+		t.Fatal("no tombstone was left for the book")
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM books WHERE").WillReturnRows(bookRows(v2))
+	read(v2)
+	read(v2)
+
+	// Delete.
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE books SET deleted_at").WillReturnRows(bookRows(&v3))
+	expectChange(mock)
+	mock.ExpectQuery("SELECT (.+) FROM book_revisions").WillReturnRows(revisionRows(v2, models.RevisionUpdate))
+	mock.ExpectCommit()
+	if err := s.DeleteBookById(ctx, &models.DeleteBookByIdRequest{BookId: testBookId, ExpectedVersion: 2}); err != nil {
+		t.Fatalf("DeleteBookById: %v", err)
+	}
+	catalog(deletedAt)
+
+	// The deleted book is not served from the cache.
+	mock.ExpectQuery("SELECT (.+) FROM books WHERE").WillReturnRows(bookRows())
+	_, err := s.GetBookById(ctx, &models.GetBookByIdRequest{BookId: testBookId})
+	if apperrors.KindOf(err) != apperrors.NotFound {
+		t.Fatalf("got %v, want not found", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCommittedOutlivesCaller makes sure a caller that gives up right after
+// the commit cannot leave the cache and catalog version behind.
+func TestCommittedOutlivesCaller(t *testing.T) {
+	s, _, _ := testStorage(t)
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	before := &models.Book{BookId: testBookId, Title: "Dune", Version: 1, UpdatedAt: at}
+	after := &models.Book{BookId: testBookId, Title: "Dune Messiah", Version: 2, UpdatedAt: at.Add(time.Minute)}
+	if err := s.redis.CacheBook(context.Background(), before); err != nil {
+		t.Fatalf("CacheBook: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.committed(ctx, models.RevisionUpdate, before, after)
+
+	if book, _, _ := s.redis.GetBook(context.Background(), testBookId); book != nil {
+		t.Errorf("version %d is still cached", book.Version)
+	}
+	if got, _ := s.redis.GetCatalogModified(context.Background()); !got.Equal(after.UpdatedAt) {
+		t.Errorf("catalog modified at %s, want %s", got, after.UpdatedAt)
+	}
+	if s.catalogUnsynced.Load() {
+		t.Error("the catalog version was not written")
+	}
+}
+
+// TestAwaitBookStopsWithContext makes sure a request waiting on another
+// process's refill gives up once its context is done, not at LockWait.
+func TestAwaitBookStopsWithContext(t *testing.T) {
+	s, _, _ := testStorage(t)
+	s.cfg.Redis.LockWait = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 2*lockPoll)
+	defer cancel()
+
+	start := time.Now()
+	if book := s.awaitBook(ctx, testBookId); book != nil {
+		t.Fatalf("got %+v from an empty cache", book)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("waited %s after the context was done", waited)
+	}
+}
+
+// TestInvalidateQueries checks which cached results a change drops: those
+// tagged with the book, and the query types it can enter or shift.
+func TestInvalidateQueries(t *testing.T) {
+	ctx := context.Background()
+	book := &models.Book{BookId: testBookId, Title: "Dune", Author: "Frank Herbert", PublishedYear: 1965, Version: 2}
+	other := &models.Book{BookId: "00000000-0000-0000-0000-000000000002", Title: "Emma", Author: "Jane Austen", PublishedYear: 1815, Version: 1}
+	deleted := *book
+	deleted.Version = 3
+
+	tests := []struct {
+		operation string
+		before    *models.Book
+		after     *models.Book
+		// wantDropped are the query types whose result without the book is
+		// dropped; one with it always is.
+		wantDropped []string
+	}{
+		{models.RevisionCreate, nil, book, []string{queryAll, queryTitle, querySearch}},
+		{models.RevisionRestore, &deleted, book, []string{queryAll, queryTitle, querySearch}},
+		{models.RevisionDelete, book, &deleted, []string{queryAll, queryTitle}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			s, _, _ := testStorage(t)
+			// cache stores a result of queryType tagged with books and
+			// reports whether it had to be loaded.
+			cache := func(queryType string, books ...*models.Book) bool {
+				t.Helper()
+				var result []*models.Book
+				loaded := false
+				err := s.cacheAside(ctx, queryType, map[string]int{"books": len(books)}, &result, func() ([]string, error) {
+					loaded = true
+					result = books
+					return bookTags(books), nil
+				})
+				if err != nil {
+					t.Fatalf("cacheAside: %v", err)
+				}
+				return loaded
+			}
+			queryTypes := []string{queryAll, queryTitle, querySearch}
+			for _, queryType := range queryTypes {
+				cache(queryType, other)
+				cache(queryType, other, tt.after)
+			}
+
+			s.invalidate(ctx, tt.operation, tt.before, tt.after)
+			for _, queryType := range queryTypes {
+				wantDropped := false
+				for _, dropped := range tt.wantDropped {
+					wantDropped = wantDropped || dropped == queryType
+				}
+				if dropped := cache(queryType, other); dropped != wantDropped {
+					t.Errorf("%s result without the book dropped: %v, want %v", queryType, dropped, wantDropped)
+				}
+				if !cache(queryType, other, tt.after) {
+					t.Errorf("%s result with the book was kept", queryType)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/ruziba3vich/boock/internal/items/apperrors"
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// enqueueDeliveriesTx queues event for every active subscription whose
+// filter matches it, in the transaction that made the change.
+func (s *Storage) enqueueDeliveriesTx(ctx context.Context, tx *sql.Tx, event *models.BookEvent, payload []byte) error {
+	subscribers := s.queryBuilder.Select("id").
+		Column("?::uuid", event.EventId).
+		Column("?::text", event.Type).
+		Column("?::jsonb", string(payload)).
+		From(s.cfg.WebhooksTable).
+		Where("active").
+		Where("(cardinality(events) = 0 OR ? = ANY(events))", event.Type)
+	query, args, err := s.queryBuilder.Insert(s.cfg.DeliveriesTable).
+		Columns("subscription_id", "event_id", "event_type", "payload").
+		Select(subscribers).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return nil
+}
+
+var (
+	webhookColumns  = []string{"id", "url", "events", "active", "created_at", "updated_at"}
+	deliveryColumns = []string{"id", "subscription_id", "event_id", "event_type", "status", "attempts", "last_status", "last_error", "next_attempt_at", "delivered_at", "created_at"}
+)
+
+type WebhookStorage struct {
+	postgres     *sql.DB
+	queryBuilder sq.StatementBuilderType
+	cfg          *config.Config
+	logger       *log.Logger
+}
+
+func NewWebhookStorage(postgres *sql.DB, queryBuilder sq.StatementBuilderType, cfg *config.Config, logger *log.Logger) repository.IWebhookRepo {
+	return &WebhookStorage{
+		postgres:     postgres,
+		queryBuilder: queryBuilder,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+func webhookNotFound(id string) error {
+	return apperrors.New(apperrors.NotFound, "webhook %s not found", id)
+}
+
+func (s *WebhookStorage) CreateWebhook(ctx context.Context, req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	active := req.Active == nil || *req.Active
+	query, args, err := s.queryBuilder.Insert(s.cfg.WebhooksTable).
+		Columns("id", "url", "events", "secret", "active").
+		Values(uuid.New().String(), req.URL, pq.Array(req.Events), req.Secret, active).
+		Suffix("RETURNING " + strings.Join(webhookColumns, ", ")).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	webhook, err := scanWebhook(s.postgres.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	webhook.Secret = req.Secret
+	return webhook, nil
+}
+
+func (s *WebhookStorage) UpdateWebhook(ctx context.Context, req *models.UpdateWebhookRequest) (*models.Webhook, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, webhookNotFound(req.Id)
+	}
+
+	update := s.queryBuilder.Update(s.cfg.WebhooksTable).
+		Set("url", req.URL).
+		Set("events", pq.Array(req.Events)).
+		Set("updated_at", sq.Expr("now()"))
+	if req.Secret != "" {
+		update = update.Set("secret", req.Secret)
+	}
+	if req.Active != nil {
+		update = update.Set("active", *req.Active)
+	}
+	query, args, err := update.Where(sq.Eq{"id": req.Id}).
+		Suffix("RETURNING " + strings.Join(webhookColumns, ", ")).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+
+	webhook, err := scanWebhook(s.postgres.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, webhookNotFound(req.Id)
+	} else if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	webhook.Secret = req.Secret
+	return webhook, nil
+}
+
+func (s *WebhookStorage) GetWebhook(ctx context.Context, req *models.GetWebhookRequest) (*models.Webhook, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, webhookNotFound(req.Id)
+	}
+	query, args, err := s.queryBuilder.Select(webhookColumns...).
+		From(s.cfg.WebhooksTable).
+		Where(sq.Eq{"id": req.Id}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+
+	webhook, err := scanWebhook(s.postgres.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, webhookNotFound(req.Id)
+	} else if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	return webhook, nil
+}
+
+func (s *WebhookStorage) ListWebhooks(ctx context.Context) (*models.ListWebhooksResponse, error) {
+	query, args, err := s.queryBuilder.Select(webhookColumns...).
+		From(s.cfg.WebhooksTable).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	return &models.ListWebhooksResponse{Webhooks: webhooks}, nil
+}
+
+// DeleteWebhook removes the subscription together with its delivery queue.
+func (s *WebhookStorage) DeleteWebhook(ctx context.Context, req *models.DeleteWebhookRequest) error {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return webhookNotFound(req.Id)
+	}
+	query, args, err := s.queryBuilder.Delete(s.cfg.WebhooksTable).
+		Where(sq.Eq{"id": req.Id}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+
+	res, err := s.postgres.ExecContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	if deleted == 0 {
+		return webhookNotFound(req.Id)
+	}
+	return nil
+}
+
+func (s *WebhookStorage) ListWebhookDeliveries(ctx context.Context, req *models.ListWebhookDeliveriesRequest) (*models.ListWebhookDeliveriesResponse, error) {
+	if _, err := s.GetWebhook(ctx, &models.GetWebhookRequest{Id: req.WebhookId}); err != nil {
+		return nil, err
+	}
+	if req.Cursor != "" {
+		return nil, apperrors.New(apperrors.Invalid, "deliveries only support page based paging")
+	}
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := clampPageLimit(req.Limit, s.cfg.Server.MaxPageSize)
+
+	where := sq.And{sq.Eq{"subscription_id": req.WebhookId}}
+	if req.Status != "" {
+		where = append(where, sq.Eq{"status": req.Status})
+	}
+
+	countQuery, countArgs, err := s.queryBuilder.Select("COUNT(*)").
+		From(s.cfg.DeliveriesTable).
+		Where(where).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	var total int64
+	if err := s.postgres.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	query, args, err := s.queryBuilder.Select(deliveryColumns...).
+		From(s.cfg.DeliveriesTable).
+		Where(where).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64((page - 1) * limit)).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	return &models.ListWebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Pagination: &models.Pagination{
+			Total:   total,
+			Page:    page,
+			Limit:   limit,
+			HasNext: int64(page*limit) < total,
+		},
+	}, nil
+}
+
+// ReplayWebhookDeliveries puts deliveries back in the queue with a fresh
+// attempt budget. A single delivery can be replayed in any state; without
+// one, every dead delivery of the webhook is.
+func (s *WebhookStorage) ReplayWebhookDeliveries(ctx context.Context, req *models.ReplayWebhookDeliveriesRequest) (*models.ReplayWebhookDeliveriesResponse, error) {
+	if _, err := s.GetWebhook(ctx, &models.GetWebhookRequest{Id: req.WebhookId}); err != nil {
+		return nil, err
+	}
+
+	where := sq.And{sq.Eq{"subscription_id": req.WebhookId}}
+	if req.DeliveryId != 0 {
+		where = append(where, sq.Eq{"id": req.DeliveryId})
+	} else {
+		where = append(where, sq.Eq{"status": models.DeliveryDead})
+	}
+
+	query, args, err := s.queryBuilder.Update(s.cfg.DeliveriesTable).
+		Set("status", models.DeliveryPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", sq.Expr("now()")).
+		Set("delivered_at", nil).
+		Where(where).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+	res, err := s.postgres.ExecContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	replayed, err := res.RowsAffected()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	if replayed == 0 && req.DeliveryId != 0 {
+		return nil, apperrors.New(apperrors.NotFound, "delivery %d of webhook %s not found", req.DeliveryId, req.WebhookId)
+	}
+	return &models.ReplayWebhookDeliveriesResponse{Replayed: replayed}, nil
+}
+
+// ClaimWebhookDeliveries leases the oldest due deliveries of active webhooks
+// and counts the attempt, like ClaimOutboxEvents does for the outbox.
+func (s *WebhookStorage) ClaimWebhookDeliveries(ctx context.Context, req *models.ClaimWebhookDeliveriesRequest) ([]*models.PendingWebhookDelivery, error) {
+	due := fmt.Sprintf(
+		"SELECT d.id FROM %s d JOIN %s w ON w.id = d.subscription_id WHERE d.status = ? AND d.next_attempt_at <= now() AND w.active ORDER BY d.id LIMIT ? FOR UPDATE OF d SKIP LOCKED",
+		s.cfg.DeliveriesTable, s.cfg.WebhooksTable,
+	)
+	query, args, err := s.queryBuilder.Update(s.cfg.DeliveriesTable+" d").
+		Set("attempts", sq.Expr("d.attempts + 1")).
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", req.Lease.Seconds())).
+		From(s.cfg.WebhooksTable + " w").
+		Where("w.id = d.subscription_id").
+		Where(sq.Expr("d.id IN ("+due+")", models.DeliveryPending, req.Limit)).
+		Suffix("RETURNING d.id, d.attempts, d.event_id, d.event_type, d.payload, w.url, w.secret").
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return nil, err
+	}
+
+	rows, err := s.postgres.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.PendingWebhookDelivery{}
+	for rows.Next() {
+		var delivery models.PendingWebhookDelivery
+		if err := rows.Scan(&delivery.Id, &delivery.Attempts, &delivery.EventId, &delivery.EventType, &delivery.Payload, &delivery.URL, &delivery.Secret); err != nil {
+			s.logger.Println(err)
+			return nil, dbError(err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	if err := rows.Err(); err != nil {
+		s.logger.Println(err)
+		return nil, dbError(err)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Id < deliveries[j].Id })
+	return deliveries, nil
+}
+
+func (s *WebhookStorage) MarkWebhookDelivered(ctx context.Context, req *models.MarkWebhookDeliveredRequest) error {
+	query, args, err := s.queryBuilder.Update(s.cfg.DeliveriesTable).
+		Set("status", models.DeliveryDelivered).
+		Set("last_status", req.Status).
+		Set("last_error", nil).
+		Set("delivered_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": req.Id}).
+		ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	if _, err := s.postgres.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return nil
+}
+
+func (s *WebhookStorage) MarkWebhookFailed(ctx context.Context, req *models.MarkWebhookFailedRequest) error {
+	update := s.queryBuilder.Update(s.cfg.DeliveriesTable).
+		Set("last_status", sql.NullInt64{Int64: int64(req.Status), Valid: req.Status != 0}).
+		Set("last_error", req.Error)
+	if req.Dead {
+		update = update.Set("status", models.DeliveryDead)
+	} else {
+		update = update.Set("next_attempt_at", req.RetryAt)
+	}
+	query, args, err := update.Where(sq.Eq{"id": req.Id}).ToSql()
+	if err != nil {
+		s.logger.Println(err)
+		return err
+	}
+	if _, err := s.postgres.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Println(err)
+		return dbError(err)
+	}
+	return nil
+}
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := row.Scan(&webhook.Id, &webhook.URL, pq.Array(&webhook.Events), &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	return &webhook, nil
+}
+
+func scanDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var (
+		delivery   models.WebhookDelivery
+		lastStatus sql.NullInt64
+		lastError  sql.NullString
+	)
+	if err := row.Scan(&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &delivery.Status, &delivery.Attempts,
+		&lastStatus, &lastError, &delivery.NextAttemptAt, &delivery.DeliveredAt, &delivery.CreatedAt); err != nil {
+		return nil, err
+	}
+	delivery.LastStatus = int(lastStatus.Int64)
+	delivery.LastError = lastError.String
+	return &delivery, nil
+}
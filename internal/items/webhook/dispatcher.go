@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// baseBackoff is the wait after the first failed attempt; it doubles with
+// every further attempt up to the configured maximum.
+const baseBackoff = 5 * time.Second
+
+type (
+	// Dispatcher sends queued deliveries to subscribers.
+	Dispatcher struct {
+		webhooks repository.IWebhookRepo
+		client   *http.Client
+		cfg      config.WebhookConfig
+		logger   *log.Logger
+	}
+)
+
+// New builds a dispatcher around client, so tests can point it at an
+// httptest server.
+func New(webhooks repository.IWebhookRepo, client *http.Client, cfg config.WebhookConfig, logger *log.Logger) *Dispatcher {
+	return &Dispatcher{
+		webhooks: webhooks,
+		client:   client,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// Run dispatches until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// A full batch means more deliveries are probably waiting.
+		if d.DispatchBatch(ctx) == d.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch sends one batch of due deliveries and returns how many it
+// claimed.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) int {
+	deliveries, err := d.webhooks.ClaimWebhookDeliveries(ctx, &models.ClaimWebhookDeliveriesRequest{
+		Limit: d.cfg.BatchSize,
+		Lease: d.cfg.Lease,
+	})
+	if err != nil {
+		d.logger.Println("Error claiming webhook deliveries:", err)
+		return 0
+	}
+
+	for _, delivery := range deliveries {
+		status, err := d.Deliver(ctx, delivery)
+		if err == nil {
+			if err := d.webhooks.MarkWebhookDelivered(ctx, &models.MarkWebhookDeliveredRequest{
+				Id:     delivery.Id,
+				Status: status,
+			}); err != nil {
+				d.logger.Println("Error marking webhook delivery as delivered:", err)
+			}
+			continue
+		}
+
+		failure := &models.MarkWebhookFailedRequest{
+			Id:     delivery.Id,
+			Status: status,
+			Error:  err.Error(),
+			Dead:   delivery.Attempts >= d.cfg.MaxAttempts,
+		}
+		if failure.Dead {
+			d.logger.Printf("Webhook delivery %d is dead after %d attempts: %s\n", delivery.Id, delivery.Attempts, err)
+		} else {
+			failure.RetryAt = time.Now().Add(d.backoff(delivery.Attempts))
+			d.logger.Printf("Webhook delivery %d failed (attempt %d), retrying at %s: %s\n", delivery.Id, delivery.Attempts, failure.RetryAt.Format(time.RFC3339), err)
+		}
+		if err := d.webhooks.MarkWebhookFailed(ctx, failure); err != nil {
+			d.logger.Println("Error recording webhook delivery failure:", err)
+		}
+	}
+	return len(deliveries)
+}
+
+// Deliver makes one signed POST of the delivery's payload. Anything but a
+// 2xx answer is an error; the status is returned whenever there was one.
+func (d *Dispatcher) Deliver(ctx context.Context, delivery *models.PendingWebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "boock-webhooks/1")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := baseBackoff
+	for i := 1; i < attempts && backoff < d.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.cfg.MaxBackoff {
+		backoff = d.cfg.MaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/boock/internal/items/config"
+	"github.com/ruziba3vich/boock/internal/items/repository"
+	"github.com/ruziba3vich/boock/internal/models"
+)
+
+// fakeWebhooks hands out pending deliveries and records how they ended.
+type fakeWebhooks struct {
+	repository.IWebhookRepo
+	pending   []*models.PendingWebhookDelivery
+	delivered []*models.MarkWebhookDeliveredRequest
+	failed    []*models.MarkWebhookFailedRequest
+}
+
+func (f *fakeWebhooks) ClaimWebhookDeliveries(_ context.Context, req *models.ClaimWebhookDeliveriesRequest) ([]*models.PendingWebhookDelivery, error) {
+	claimed := f.pending
+	if len(claimed) > req.Limit {
+		claimed = claimed[:req.Limit]
+	}
+	f.pending = f.pending[len(claimed):]
+	return claimed, nil
+}
+
+func (f *fakeWebhooks) MarkWebhookDelivered(_ context.Context, req *models.MarkWebhookDeliveredRequest) error {
+	f.delivered = append(f.delivered, req)
+	return nil
+}
+
+func (f *fakeWebhooks) MarkWebhookFailed(_ context.Context, req *models.MarkWebhookFailedRequest) error {
+	f.failed = append(f.failed, req)
+	return nil
+}
+
+func testConfig() config.WebhookConfig {
+	return config.WebhookConfig{
+		BatchSize:   10,
+		MaxAttempts: 3,
+		MaxBackoff:  time.Minute,
+		Timeout:     time.Second,
+	}
+}
+
+func newTestDispatcher(webhooks repository.IWebhookRepo, client *http.Client) *Dispatcher {
+	return New(webhooks, client, testConfig(), log.New(io.Discard, "", 0))
+}
+
+// receiver answers every delivery with status and hands over what it got.
+func receiver(t *testing.T, status int) (*httptest.Server, <-chan *http.Request, <-chan []byte) {
+	requests := make(chan *http.Request, 10)
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, bodies
+}
+
+func TestDeliverSignsTheRequest(t *testing.T) {
+	server, requests, bodies := receiver(t, http.StatusNoContent)
+	delivery := &models.PendingWebhookDelivery{
+		Id:        7,
+		EventType: "book.created",
+		Payload:   []byte(`{"book_id":"1"}`),
+		URL:       server.URL,
+		Secret:    "secret",
+	}
+
+	status, err := newTestDispatcher(&fakeWebhooks{}, server.Client()).Deliver(context.Background(), delivery)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Deliver = %d, %v, want 204 and no error", status, err)
+	}
+
+	req, body := <-requests, <-bodies
+	if req.Method != http.MethodPost || string(body) != string(delivery.Payload) {
+		t.Errorf("got %s with %q", req.Method, body)
+	}
+	if got := req.Header.Get(EventHeader); got != "book.created" {
+		t.Errorf("%s = %q", EventHeader, got)
+	}
+	if got := req.Header.Get(DeliveryHeader); got != "7" {
+		t.Errorf("%s = %q", DeliveryHeader, got)
+	}
+	if !Verify("secret", req.Header.Get(TimestampHeader), req.Header.Get(SignatureHeader), body, time.Minute) {
+		t.Error("the receiver could not verify the signature")
+	}
+}
+
+func TestDeliverRejectsNon2xx(t *testing.T) {
+	for _, status := range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusInternalServerError} {
+		server, _, _ := receiver(t, status)
+		got, err := newTestDispatcher(&fakeWebhooks{}, server.Client()).Deliver(context.Background(), &models.PendingWebhookDelivery{URL: server.URL})
+		if err == nil || got != status {
+			t.Errorf("Deliver to a %d receiver = %d, %v, want %d and an error", status, got, err, status)
+		}
+	}
+}
+
+func TestDeliverUnreachable(t *testing.T) {
+	server, _, _ := receiver(t, http.StatusOK)
+	server.Close()
+	status, err := newTestDispatcher(&fakeWebhooks{}, server.Client()).Deliver(context.Background(), &models.PendingWebhookDelivery{URL: server.URL})
+	if err == nil || status != 0 {
+		t.Errorf("Deliver to a closed receiver = %d, %v, want 0 and an error", status, err)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	d := newTestDispatcher(&fakeWebhooks{}, http.DefaultClient)
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{4, 40 * time.Second},
+		{5, time.Minute},
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDispatchBatch(t *testing.T) {
+	ok, _, _ := receiver(t, http.StatusOK)
+	broken, _, _ := receiver(t, http.StatusServiceUnavailable)
+	webhooks := &fakeWebhooks{pending: []*models.PendingWebhookDelivery{
+		{Id: 1, Attempts: 1, URL: ok.URL},
+		{Id: 2, Attempts: 2, URL: broken.URL},
+		// The last attempt MaxAttempts allows.
+		{Id: 3, Attempts: 3, URL: broken.URL},
+	}}
+
+	start := time.Now()
+	if claimed := newTestDispatcher(webhooks, http.DefaultClient).DispatchBatch(context.Background()); claimed != 3 {
+		t.Fatalf("DispatchBatch claimed %d, want 3", claimed)
+	}
+
+	if len(webhooks.delivered) != 1 || webhooks.delivered[0].Id != 1 || webhooks.delivered[0].Status != http.StatusOK {
+		t.Errorf("delivered = %+v, want delivery 1 with 200", webhooks.delivered)
+	}
+	if len(webhooks.failed) != 2 {
+		t.Fatalf("failed = %+v, want deliveries 2 and 3", webhooks.failed)
+	}
+	retried, dead := webhooks.failed[0], webhooks.failed[1]
+	if retried.Id != 2 || retried.Dead || retried.Status != http.StatusServiceUnavailable {
+		t.Errorf("delivery 2 = %+v, want a retry", retried)
+	}
+	if wait := retried.RetryAt.Sub(start); wait < 10*time.Second || wait > 11*time.Second {
+		t.Errorf("delivery 2 is retried after %s, want 10s", wait)
+	}
+	if dead.Id != 3 || !dead.Dead {
+		t.Errorf("delivery 3 = %+v, want it dead after MaxAttempts", dead)
+	}
+}
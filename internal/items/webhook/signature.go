@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature header value for a delivery: HMAC-SHA256 with
+// the subscription secret over "<timestamp>.<body>". Binding the timestamp
+// lets receivers reject replayed requests.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify is what a receiver runs on the headers and body it got. It rejects
+// signatures older than tolerance, unless tolerance is zero.
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration) bool {
+	seconds, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil || !strings.HasPrefix(signatureHeader, signaturePrefix) {
+		return false
+	}
+	timestamp := time.Unix(seconds, 0)
+	if tolerance > 0 && time.Since(timestamp).Abs() > tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signatureHeader))
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"type":"book.created"}`)
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("secret", now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		tolerance time.Duration
+		want      bool
+	}{
+		{"valid", "secret", timestamp, signature, body, time.Minute, true},
+		{"other secret", "other", timestamp, signature, body, time.Minute, false},
+		{"changed body", "secret", timestamp, signature, []byte(`{"type":"book.deleted"}`), time.Minute, false},
+		{"changed timestamp", "secret", strconv.FormatInt(now.Unix()+1, 10), signature, body, time.Minute, false},
+		{"malformed timestamp", "secret", "yesterday", signature, body, time.Minute, false},
+		{"missing prefix", "secret", timestamp, signature[len(signaturePrefix):], body, time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, tt.tolerance); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTolerance(t *testing.T) {
+	body := []byte("{}")
+	old := time.Now().Add(-time.Hour)
+	timestamp := strconv.FormatInt(old.Unix(), 10)
+	signature := Sign("secret", old, body)
+
+	if Verify("secret", timestamp, signature, body, time.Minute) {
+		t.Error("a signature older than the tolerance verified")
+	}
+	if !Verify("secret", timestamp, signature, body, 0) {
+		t.Error("a zero tolerance did not accept an old signature")
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+type (
+	AuditEntry struct {
+		Id         int64                  `json:"id"`
+		OccurredAt time.Time              `json:"occurred_at"`
+		Actor      string                 `json:"actor,omitempty"`
+		IP         string                 `json:"ip"`
+		Method     string                 `json:"method"`
+		Route      string                 `json:"route"`
+		RequestId  string                 `json:"request_id"`
+		Operation  string                 `json:"operation,omitempty"`
+		BookId     string                 `json:"book_id,omitempty"`
+		Diff       map[string]AuditChange `json:"diff,omitempty"`
+		Status     int                    `json:"status"`
+		Outcome    string                 `json:"outcome"`
+		Error      string                 `json:"error,omitempty"`
+		PrevHash   string                 `json:"prev_hash"`
+		Hash       string                 `json:"hash"`
+	}
+	// AuditChange holds the JSON values of one book field before and after a
+	// mutation; nil means the book did not exist on that side.
+	AuditChange struct {
+		Before interface{} `json:"before"`
+		After  interface{} `json:"after"`
+	}
+	QueryAuditLogRequest struct {
+		Actor string    `json:"actor"`
+		From  time.Time `json:"from"`
+		To    time.Time `json:"to"`
+		Paging
+	}
+	QueryAuditLogResponse struct {
+		Entries    []*AuditEntry `json:"entries"`
+		Pagination *Pagination   `json:"pagination,omitempty"`
+	}
+	VerifyAuditLogResponse struct {
+		Checked int64 `json:"checked"`
+		Valid   bool  `json:"valid"`
+		// BrokenAt is the first entry whose hash does not match its content.
+		BrokenAt int64 `json:"broken_at,omitempty"`
+	}
+)
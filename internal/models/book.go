@@ -1,45 +1,154 @@
 package models
 
+import "time"
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+
+	RevisionSnapshot = "snapshot"
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+	RevisionRevert   = "revert"
+)
+
 type (
 	Book struct {
-		BookId        string `json:"book_id"`
-		Title         string `json:"title"`
-		Author        string `json:"author"`
-		PublisherYear int    `json:"published_year"`
+		BookId        string     `json:"book_id"`
+		Title         string     `json:"title"`
+		Author        string     `json:"author"`
+		PublishedYear int        `json:"published_year"`
+		Version       int64      `json:"version"`
+		UpdatedAt     time.Time  `json:"updated_at"`
+		DeletedAt     *time.Time `json:"deleted_at,omitempty"`
 	}
 
 	CreateBookRequest struct {
 		Title         string `json:"title"`
 		Author        string `json:"author"`
-		PublisherYear int    `json:"published_year"`
+		PublishedYear int    `json:"published_year"`
 	}
 	UpdateBookRequest struct {
 		BookId        string `json:"book_id"`
 		Title         string `json:"title"`
 		Author        string `json:"author"`
-		PublisherYear int    `json:"published_year"`
+		PublishedYear int    `json:"published_year"`
+		// ExpectedVersion comes from If-Match; zero matches any version.
+		ExpectedVersion int64 `json:"-"`
+	}
+	Paging struct {
+		Page   int    `json:"page"`
+		Limit  int    `json:"limit"`
+		Cursor string `json:"cursor"`
+	}
+	PatchBookRequest struct {
+		BookId    string `json:"book_id"`
+		PatchType string `json:"patch_type"`
+		Patch     []byte `json:"patch"`
+		// ExpectedVersion comes from If-Match; zero matches any version.
+		ExpectedVersion int64 `json:"-"`
+		// Document is the form of the book the patch was written against;
+		// nil means the JSON of Book itself.
+		Document BookDocument `json:"-"`
+	}
+	// BookDocument maps a book to and from the JSON a client sees, so a
+	// patch applies to the field names of the API it came through.
+	BookDocument interface {
+		Marshal(*Book) ([]byte, error)
+		Unmarshal([]byte) (*Book, error)
+		// IdField is the name the document gives the book ID.
+		IdField() string
 	}
 	GetAllBooksRequest struct {
-		Page  int `json:"page"`
-		Limit int `json:"limit"`
+		Filter string `json:"filter"`
+		Sort   string `json:"sort"`
+		Paging
 	}
 	GetBookByIdRequest struct {
 		BookId string `json:"book_id"`
+		// AsOf, when set, asks for the book as it was at that moment.
+		AsOf time.Time `json:"as_of"`
 	}
 	GetBooksByAuthorRequest struct {
 		Author string `json:"author"`
+		Paging
 	}
 	GetBooksByNameRequest struct {
 		BookName string `json:"book_name"`
+		Paging
 	}
 	GetSeveralResponse struct {
-		Books []*Book `json:"books"`
+		Books      []*Book     `json:"books"`
+		Pagination *Pagination `json:"pagination,omitempty"`
+	}
+	Pagination struct {
+		Total      int64  `json:"total,omitempty"`
+		Page       int    `json:"page,omitempty"`
+		Limit      int    `json:"limit"`
+		HasNext    bool   `json:"has_next"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
 	}
 	SearchBooksRequest struct {
 		Search string `json:"search"`
+		Limit  int    `json:"limit"`
+	}
+	SearchBooksResponse struct {
+		Results []*SearchResult `json:"results"`
+	}
+	SearchResult struct {
+		Book       *Book            `json:"book"`
+		Rank       float64          `json:"rank"`
+		Highlights SearchHighlights `json:"highlights"`
+	}
+	SearchHighlights struct {
+		Title  string `json:"title"`
+		Author string `json:"author"`
+	}
+	CatalogVersion struct {
+		// ModifiedAt is when any book was last created, changed or deleted.
+		ModifiedAt time.Time `json:"modified_at"`
+	}
+	ListTrashRequest struct {
+		Paging
+	}
+	RestoreBookRequest struct {
+		BookId string `json:"book_id"`
+	}
+	PurgeDeletedBooksRequest struct {
+		DeletedBefore time.Time `json:"deleted_before"`
+	}
+	PurgeDeletedBooksResponse struct {
+		Purged int64 `json:"purged"`
+	}
+	BookRevision struct {
+		// Revision is the version the book had after the change.
+		Revision   int64     `json:"revision"`
+		Operation  string    `json:"operation"`
+		ChangedBy  string    `json:"changed_by,omitempty"`
+		RecordedAt time.Time `json:"recorded_at"`
+		Book       *Book     `json:"book"`
+	}
+	GetBookHistoryRequest struct {
+		BookId string `json:"book_id"`
+		Paging
+	}
+	GetBookHistoryResponse struct {
+		Revisions  []*BookRevision `json:"revisions"`
+		Pagination *Pagination     `json:"pagination,omitempty"`
+	}
+	RevertBookRequest struct {
+		BookId   string `json:"book_id"`
+		Revision int64  `json:"revision"`
+		// ExpectedVersion comes from If-Match; zero matches any version.
+		ExpectedVersion int64 `json:"-"`
 	}
 	DeleteBookByIdRequest struct {
 		BookId string `json:"book_id"`
+		// ExpectedVersion comes from If-Match; zero matches any version.
+		ExpectedVersion int64 `json:"-"`
 	}
 )
 
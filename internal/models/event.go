@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	EventBookCreated = "book.created"
+	EventBookUpdated = "book.updated"
+	EventBookDeleted = "book.deleted"
+)
+
+type (
+	// BookEvent is what consumers of the book change stream receive. Delivery
+	// is at least once, so they should deduplicate on EventId.
+	BookEvent struct {
+		EventId    string    `json:"event_id"`
+		Type       string    `json:"type"`
+		BookId     string    `json:"book_id"`
+		Version    int64     `json:"version"`
+		OccurredAt time.Time `json:"occurred_at"`
+		Book       *Book     `json:"book"`
+	}
+	// StreamedBookEvent is a BookEvent together with its Redis stream ID,
+	// which doubles as the SSE event ID.
+	StreamedBookEvent struct {
+		Id    string     `json:"id"`
+		Event *BookEvent `json:"event"`
+	}
+	OutboxEvent struct {
+		Id       int64
+		Attempts int
+		Event    *BookEvent
+	}
+	ClaimOutboxEventsRequest struct {
+		Limit int
+		// Lease is how long claimed events are hidden from other relays.
+		Lease time.Duration
+	}
+	MarkOutboxPublishedRequest struct {
+		Id       int64
+		StreamId string
+	}
+	MarkOutboxFailedRequest struct {
+		Id      int64
+		Error   string
+		RetryAt time.Time
+	}
+	PurgePublishedOutboxEventsRequest struct {
+		PublishedBefore time.Time
+		// Limit caps how many events one call deletes.
+		Limit int
+	}
+	PurgePublishedOutboxEventsResponse struct {
+		Purged int64
+	}
+)
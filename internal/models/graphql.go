@@ -0,0 +1,18 @@
+package models
+
+type (
+	GraphQLRequest struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+		Extensions    GraphQLExtensions      `json:"extensions"`
+	}
+	GraphQLExtensions struct {
+		PersistedQuery *PersistedQuery `json:"persistedQuery,omitempty"`
+	}
+	// PersistedQuery follows the automatic persisted query protocol.
+	PersistedQuery struct {
+		Version    int    `json:"version"`
+		Sha256Hash string `json:"sha256Hash"`
+	}
+)
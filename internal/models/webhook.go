@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type (
+	Webhook struct {
+		Id  string `json:"id"`
+		URL string `json:"url"`
+		// Events limits deliveries to these event types; empty means all.
+		Events []string `json:"events"`
+		// Secret is only ever returned when it is set.
+		Secret    string    `json:"secret,omitempty"`
+		Active    bool      `json:"active"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	CreateWebhookRequest struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+		// Secret is generated when left empty.
+		Secret string `json:"secret"`
+		Active *bool  `json:"active"`
+	}
+	UpdateWebhookRequest struct {
+		Id     string   `json:"id"`
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+		// Secret is kept when left empty.
+		Secret string `json:"secret"`
+		Active *bool  `json:"active"`
+	}
+	GetWebhookRequest struct {
+		Id string `json:"id"`
+	}
+	ListWebhooksResponse struct {
+		Webhooks []*Webhook `json:"webhooks"`
+	}
+	DeleteWebhookRequest struct {
+		Id string `json:"id"`
+	}
+	WebhookDelivery struct {
+		Id            int64      `json:"id"`
+		WebhookId     string     `json:"webhook_id"`
+		EventId       string     `json:"event_id"`
+		EventType     string     `json:"event_type"`
+		Status        string     `json:"status"`
+		Attempts      int        `json:"attempts"`
+		LastStatus    int        `json:"last_status,omitempty"`
+		LastError     string     `json:"last_error,omitempty"`
+		NextAttemptAt time.Time  `json:"next_attempt_at"`
+		DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+	ListWebhookDeliveriesRequest struct {
+		WebhookId string `json:"webhook_id"`
+		// Status, when set, only lists deliveries in that state.
+		Status string `json:"status"`
+		Paging
+	}
+	ListWebhookDeliveriesResponse struct {
+		Deliveries []*WebhookDelivery `json:"deliveries"`
+		Pagination *Pagination        `json:"pagination,omitempty"`
+	}
+	ReplayWebhookDeliveriesRequest struct {
+		WebhookId string `json:"webhook_id"`
+		// DeliveryId picks one delivery; zero replays every dead delivery.
+		DeliveryId int64 `json:"delivery_id"`
+	}
+	ReplayWebhookDeliveriesResponse struct {
+		Replayed int64 `json:"replayed"`
+	}
+	// PendingWebhookDelivery is a claimed delivery with what it takes to send it.
+	PendingWebhookDelivery struct {
+		Id        int64
+		Attempts  int
+		EventId   string
+		EventType string
+		Payload   []byte
+		URL       string
+		Secret    string
+	}
+	ClaimWebhookDeliveriesRequest struct {
+		Limit int
+		// Lease is how long claimed deliveries are hidden from other dispatchers.
+		Lease time.Duration
+	}
+	MarkWebhookDeliveredRequest struct {
+		Id     int64
+		Status int
+	}
+	MarkWebhookFailedRequest struct {
+		Id     int64
+		Status int
+		Error  string
+		// RetryAt is ignored when Dead is set.
+		RetryAt time.Time
+		Dead    bool
+	}
+)
@@ -1,15 +1,70 @@
 package redisCl
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
 	"github.com/go-redis/redis/v8"
 	"github.com/ruziba3vich/boock/internal/items/config"
 )
 
-func NewRedisDB(cfg *config.Config) (*redis.Client, error) {
-	rdb := redis.NewClient(&redis.Options{
+const pingTimeout = 5 * time.Second
+
+// NewRedisDB connects to Redis and pings it, retrying with a doubling
+// backoff, so the service can start alongside a Redis that is still coming
+// up. It fails once ConnectAttempts pings have.
+func NewRedisDB(cfg *config.Config, logger *log.Logger) (*redis.Client, error) {
+	options := &redis.Options{
 		Addr:     cfg.Redis.Host + ":" + cfg.Redis.Port,
-		Password: "",
-		DB:       0,
-	})
-	return rdb, nil
+		Username: cfg.Redis.Username,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	}
+	if cfg.Redis.TLS {
+		tlsConfig, err := tlsConfig(cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		options.TLSConfig = tlsConfig
+	}
+	rdb := redis.NewClient(options)
+
+	backoff := cfg.Redis.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err := rdb.Ping(ctx).Err()
+		cancel()
+		if err == nil {
+			return rdb, nil
+		}
+		if attempt >= cfg.Redis.ConnectAttempts {
+			rdb.Close()
+			return nil, fmt.Errorf("redis at %s: %w", options.Addr, err)
+		}
+		logger.Printf("Redis at %s is unreachable (attempt %d of %d), retrying in %s: %s\n", options.Addr, attempt, cfg.Redis.ConnectAttempts, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func tlsConfig(cfg config.RedisConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+	if cfg.TLSCAFile == "" {
+		return tlsConfig, nil
+	}
+	pem, err := os.ReadFile(cfg.TLSCAFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates in " + cfg.TLSCAFile)
+	}
+	return tlsConfig, nil
 }
@@ -0,0 +1,52 @@
+package reqmeta
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// MaxActorLength is how many characters of an actor are kept, which is as
+// many as the columns that record it hold.
+const MaxActorLength = 255
+
+type (
+	// Meta describes the request a piece of work is done for, so layers below
+	// the HTTP handlers can record it without depending on gin.
+	Meta struct {
+		// Actor is whoever the caller says they are; empty when unknown.
+		Actor     string
+		IP        string
+		Method    string
+		Route     string
+		RequestId string
+	}
+
+	contextKey struct{}
+)
+
+func With(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, contextKey{}, meta)
+}
+
+func From(ctx context.Context) Meta {
+	meta, _ := ctx.Value(contextKey{}).(Meta)
+	return meta
+}
+
+// Actor cleans up the actor a caller claims to be, so that recording it
+// cannot fail a write: it is trimmed, invalid UTF-8 and control characters
+// are dropped and it is cut to MaxActorLength characters.
+func Actor(claimed string) string {
+	actor := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(claimed, ""))
+	actor = strings.TrimSpace(actor)
+	if runes := []rune(actor); len(runes) > MaxActorLength {
+		actor = strings.TrimSpace(string(runes[:MaxActorLength]))
+	}
+	return actor
+}
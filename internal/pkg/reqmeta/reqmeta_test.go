@@ -0,0 +1,27 @@
+package reqmeta
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestActor(t *testing.T) {
+	tests := []struct {
+		name    string
+		claimed string
+		want    string
+	}{
+		{"trims", "  alice \t", "alice"},
+		{"drops control characters", "al\x00ice\n", "alice"},
+		{"drops invalid UTF-8", "al\xffice", "alice"},
+		{"keeps the limit", strings.Repeat("ж", MaxActorLength), strings.Repeat("ж", MaxActorLength)},
+		{"cuts characters, not bytes", strings.Repeat("ж", MaxActorLength+1), strings.Repeat("ж", MaxActorLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Actor(tt.claimed); got != tt.want {
+				t.Errorf("Actor(%q) = %q, want %q", tt.claimed, got, tt.want)
+			}
+		})
+	}
+}
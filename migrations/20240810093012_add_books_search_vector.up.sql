@@ -0,0 +1,8 @@
+
+ALTER TABLE books
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(author, '')), 'B')
+    ) STORED;
+
+CREATE INDEX books_search_vector_idx ON books USING GIN (search_vector);
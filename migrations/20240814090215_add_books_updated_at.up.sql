@@ -0,0 +1,2 @@
+
+ALTER TABLE books ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,4 @@
+
+DROP INDEX IF EXISTS books_deleted_at_idx;
+
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
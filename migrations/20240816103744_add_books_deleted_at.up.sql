@@ -0,0 +1,4 @@
+
+ALTER TABLE books ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,18 @@
+
+CREATE TABLE book_revisions (
+    book_id UUID NOT NULL,
+    version BIGINT NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    published_year INT NOT NULL,
+    changed_by VARCHAR(255),
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (book_id, version)
+);
+
+CREATE INDEX book_revisions_recorded_at_idx ON book_revisions (book_id, recorded_at);
+
+INSERT INTO book_revisions (book_id, version, operation, author, title, published_year, recorded_at)
+SELECT book_id, version, CASE WHEN deleted_at IS NULL THEN 'snapshot' ELSE 'delete' END, author, title, published_year, updated_at
+FROM books;
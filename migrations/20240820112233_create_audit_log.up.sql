@@ -0,0 +1,31 @@
+
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    actor VARCHAR(255),
+    ip VARCHAR(64) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    operation VARCHAR(16),
+    book_id UUID,
+    diff JSONB,
+    status INT NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    error TEXT,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX audit_log_actor_idx ON audit_log (actor, occurred_at);
+CREATE INDEX audit_log_occurred_at_idx ON audit_log (occurred_at);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
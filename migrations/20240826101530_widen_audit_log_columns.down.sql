@@ -0,0 +1,4 @@
+
+ALTER TABLE audit_log
+    ALTER COLUMN book_id TYPE UUID USING book_id::uuid,
+    ALTER COLUMN actor TYPE VARCHAR(255);
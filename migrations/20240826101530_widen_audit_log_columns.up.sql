@@ -0,0 +1,6 @@
+
+-- Failed attempts are audited too, and they can name a book id that is not a
+-- UUID or carry any actor, so neither may make the insert fail.
+ALTER TABLE audit_log
+    ALTER COLUMN book_id TYPE TEXT USING book_id::text,
+    ALTER COLUMN actor TYPE TEXT;
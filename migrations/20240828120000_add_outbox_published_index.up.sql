@@ -0,0 +1,3 @@
+
+-- Published events are swept after the retention window, oldest first.
+CREATE INDEX outbox_published_idx ON outbox (published_at) WHERE published_at IS NOT NULL;